@@ -2,6 +2,7 @@ package ast
 
 import (
 	"bytes"
+	"math/big"
 	"monkey/token"
 	"strings"
 )
@@ -10,6 +11,7 @@ import (
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Pos() token.Position
 }
 
 // Statement interface represents a statement
@@ -38,6 +40,15 @@ func (p *Program) TokenLiteral() string {
 	return ""
 }
 
+// Pos returns the position of the first statement
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+
+	return token.Position{}
+}
+
 func (p *Program) String() string {
 	var out bytes.Buffer
 
@@ -59,10 +70,52 @@ func (i *Identifier) TokenLiteral() string {
 	return i.Token.Literal
 }
 
+// Pos returns the position of the token associated with this node
+func (i *Identifier) Pos() token.Position {
+	return i.Token.Position
+}
+
 func (i *Identifier) String() string {
 	return i.Value
 }
 
+// LetStatement represents a declaration in the innermost scope of the form:
+// let x = 1 or const x = 1
+type LetStatement struct {
+	Token token.Token // The 'let' or 'const' token
+	Name  *Identifier
+	Value Expression
+}
+
+func (ls *LetStatement) statementNode() {}
+
+// TokenLiteral prints the literal value of the token associated with this node
+func (ls *LetStatement) TokenLiteral() string {
+	return ls.Token.Literal
+}
+
+// Pos returns the position of the token associated with this node
+func (ls *LetStatement) Pos() token.Position {
+	return ls.Token.Position
+}
+
+// String returns a stringified version of the AST for debugging
+func (ls *LetStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ls.TokenLiteral() + " ")
+	out.WriteString(ls.Name.String())
+	out.WriteString(" = ")
+
+	if ls.Value != nil {
+		out.WriteString(ls.Value.String())
+	}
+
+	out.WriteString(";")
+
+	return out.String()
+}
+
 type ReturnStatement struct {
 	Token       token.Token
 	ReturnValue Expression
@@ -74,6 +127,11 @@ func (rs *ReturnStatement) TokenLiteral() string {
 	return rs.Token.Literal
 }
 
+// Pos returns the position of the token associated with this node
+func (rs *ReturnStatement) Pos() token.Position {
+	return rs.Token.Position
+}
+
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
 	out.WriteString(rs.TokenLiteral() + " ")
@@ -98,6 +156,11 @@ func (es *ExpressionStatement) TokenLiteral() string {
 	return es.Token.Literal
 }
 
+// Pos returns the position of the token associated with this node
+func (es *ExpressionStatement) Pos() token.Position {
+	return es.Token.Position
+}
+
 func (es *ExpressionStatement) String() string {
 	if es.Expression != nil {
 		return es.Expression.String()
@@ -109,6 +172,8 @@ func (es *ExpressionStatement) String() string {
 type IntegerLiteral struct {
 	Token token.Token
 	Value int64
+	// Big holds the value of literals too large for Value
+	Big *big.Int
 }
 
 type FloatLiteral struct {
@@ -122,6 +187,11 @@ func (fl *FloatLiteral) TokenLiteral() string {
 	return fl.Token.Literal
 }
 
+// Pos returns the position of the token associated with this node
+func (fl *FloatLiteral) Pos() token.Position {
+	return fl.Token.Position
+}
+
 func (fl *FloatLiteral) String() string {
 	return fl.Token.Literal
 }
@@ -132,6 +202,11 @@ func (il *IntegerLiteral) TokenLiteral() string {
 	return il.Token.Literal
 }
 
+// Pos returns the position of the token associated with this node
+func (il *IntegerLiteral) Pos() token.Position {
+	return il.Token.Position
+}
+
 func (il *IntegerLiteral) String() string {
 	return il.Token.Literal
 }
@@ -148,6 +223,11 @@ func (pe *PrefixExpression) TokenLiteral() string {
 	return pe.Token.Literal
 }
 
+// Pos returns the position of the token associated with this node
+func (pe *PrefixExpression) Pos() token.Position {
+	return pe.Token.Position
+}
+
 func (pe *PrefixExpression) String() string {
 	var out bytes.Buffer
 
@@ -172,6 +252,11 @@ func (oe *InfixExpression) TokenLiteral() string {
 	return oe.Token.Literal
 }
 
+// Pos returns the position of the token associated with this node
+func (oe *InfixExpression) Pos() token.Position {
+	return oe.Token.Position
+}
+
 func (oe *InfixExpression) String() string {
 	var out bytes.Buffer
 
@@ -195,6 +280,11 @@ func (b *Boolean) TokenLiteral() string {
 	return b.Token.Literal
 }
 
+// Pos returns the position of the token associated with this node
+func (b *Boolean) Pos() token.Position {
+	return b.Token.Position
+}
+
 func (b *Boolean) String() string {
 	return b.Token.Literal
 }
@@ -209,6 +299,11 @@ func (b *Null) TokenLiteral() string {
 	return b.Token.Literal
 }
 
+// Pos returns the position of the token associated with this node
+func (b *Null) Pos() token.Position {
+	return b.Token.Position
+}
+
 func (b *Null) String() string {
 	return b.Token.Literal
 }
@@ -226,6 +321,11 @@ func (ie *IfExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
 
+// Pos returns the position of the token associated with this node
+func (ie *IfExpression) Pos() token.Position {
+	return ie.Token.Position
+}
+
 func (ie *IfExpression) String() string {
 	var out bytes.Buffer
 
@@ -253,6 +353,11 @@ func (bs *BlockStatement) TokenLiteral() string {
 	return bs.Token.Literal
 }
 
+// Pos returns the position of the token associated with this node
+func (bs *BlockStatement) Pos() token.Position {
+	return bs.Token.Position
+}
+
 func (bs *BlockStatement) String() string {
 	var out bytes.Buffer
 
@@ -263,10 +368,461 @@ func (bs *BlockStatement) String() string {
 	return out.String()
 }
 
+// ThrowStatement represents a statement of the form: throw expr
+type ThrowStatement struct {
+	Token token.Token // The 'throw' token
+	Value Expression
+}
+
+func (ts *ThrowStatement) statementNode() {}
+
+// TokenLiteral prints the literal value of the token associated with this node
+func (ts *ThrowStatement) TokenLiteral() string {
+	return ts.Token.Literal
+}
+
+// Pos returns the position of the token associated with this node
+func (ts *ThrowStatement) Pos() token.Position {
+	return ts.Token.Position
+}
+
+// String returns a stringified version of the AST for debugging
+func (ts *ThrowStatement) String() string {
+	return ts.TokenLiteral() + " " + ts.Value.String() + ";"
+}
+
+// YieldStatement represents a statement of the form: yield expr
+// The value is nil for a bare yield.
+type YieldStatement struct {
+	Token token.Token // The 'yield' token
+	Value Expression
+}
+
+func (ys *YieldStatement) statementNode() {}
+
+// TokenLiteral prints the literal value of the token associated with this node
+func (ys *YieldStatement) TokenLiteral() string {
+	return ys.Token.Literal
+}
+
+// Pos returns the position of the token associated with this node
+func (ys *YieldStatement) Pos() token.Position {
+	return ys.Token.Position
+}
+
+// String returns a stringified version of the AST for debugging
+func (ys *YieldStatement) String() string {
+	if ys.Value == nil {
+		return ys.TokenLiteral() + ";"
+	}
+
+	return ys.TokenLiteral() + " " + ys.Value.String() + ";"
+}
+
+// StructStatement represents a declaration of the form:
+// struct Name { field, other, method: fn(...) { ... } }
+// Methods refer to the instance they are called on as self.
+type StructStatement struct {
+	Token   token.Token // The 'struct' token
+	Name    *Identifier
+	Fields  []*Identifier
+	Methods []*StructMethod
+}
+
+// StructMethod is a method of a struct declaration
+type StructMethod struct {
+	Name     *Identifier
+	Function *FunctionLiteral
+}
+
+func (ss *StructStatement) statementNode() {}
+
+// TokenLiteral prints the literal value of the token associated with this node
+func (ss *StructStatement) TokenLiteral() string {
+	return ss.Token.Literal
+}
+
+// Pos returns the position of the token associated with this node
+func (ss *StructStatement) Pos() token.Position {
+	return ss.Token.Position
+}
+
+// String returns a stringified version of the AST for debugging
+func (ss *StructStatement) String() string {
+	var out bytes.Buffer
+	var members []string
+
+	for _, field := range ss.Fields {
+		members = append(members, field.String())
+	}
+
+	for _, method := range ss.Methods {
+		members = append(members, method.Name.String()+": "+method.Function.String())
+	}
+
+	out.WriteString(ss.TokenLiteral() + " ")
+	out.WriteString(ss.Name.String())
+	out.WriteString(" { ")
+	out.WriteString(strings.Join(members, ", "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+// TryExpression represents an expression of the form:
+// try { ... } catch (e) { ... } finally { ... }
+// At least one of the catch or finally clauses is present and the catch
+// parameter is optional.
+type TryExpression struct {
+	Token     token.Token // The 'try' token
+	Block     *BlockStatement
+	Parameter *Identifier
+	Catch     *BlockStatement
+	Finally   *BlockStatement
+}
+
+func (te *TryExpression) expressionNode() {}
+
+// TokenLiteral prints the literal value of the token associated with this node
+func (te *TryExpression) TokenLiteral() string {
+	return te.Token.Literal
+}
+
+// Pos returns the position of the token associated with this node
+func (te *TryExpression) Pos() token.Position {
+	return te.Token.Position
+}
+
+// String returns a stringified version of the AST for debugging
+func (te *TryExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("try ")
+	out.WriteString(te.Block.String())
+
+	if te.Catch != nil {
+		out.WriteString(" catch ")
+
+		if te.Parameter != nil {
+			out.WriteString("(" + te.Parameter.String() + ") ")
+		}
+
+		out.WriteString(te.Catch.String())
+	}
+
+	if te.Finally != nil {
+		out.WriteString(" finally ")
+		out.WriteString(te.Finally.String())
+	}
+
+	return out.String()
+}
+
+// MatchExpression represents an expression of the form:
+// match (value) { pattern => result, pattern if guard => { ... }, _ => default }
+type MatchExpression struct {
+	Token   token.Token // The 'match' token
+	Subject Expression
+	Arms    []*MatchArm
+}
+
+// MatchArm is a single pattern of a match expression with its optional guard
+// and the body evaluated when it matches
+type MatchArm struct {
+	Pattern Expression
+	Guard   Expression
+	Body    *BlockStatement
+}
+
+func (me *MatchExpression) expressionNode() {}
+
+// TokenLiteral prints the literal value of the token associated with this node
+func (me *MatchExpression) TokenLiteral() string {
+	return me.Token.Literal
+}
+
+// Pos returns the position of the token associated with this node
+func (me *MatchExpression) Pos() token.Position {
+	return me.Token.Position
+}
+
+// String returns a stringified version of the AST for debugging
+func (me *MatchExpression) String() string {
+	var out bytes.Buffer
+	var arms []string
+
+	for _, arm := range me.Arms {
+		arms = append(arms, arm.String())
+	}
+
+	out.WriteString("match (")
+	out.WriteString(me.Subject.String())
+	out.WriteString(") { ")
+	out.WriteString(strings.Join(arms, ", "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+// String returns a stringified version of the AST for debugging
+func (ma *MatchArm) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ma.Pattern.String())
+
+	if ma.Guard != nil {
+		out.WriteString(" if " + ma.Guard.String())
+	}
+
+	out.WriteString(" => ")
+	out.WriteString(ma.Body.String())
+
+	return out.String()
+}
+
+// SelectExpression represents an expression of the form:
+// select { v = recv(ch) => { ... }, send(ch, x) => result, _ => default }
+type SelectExpression struct {
+	Token token.Token // The 'select' token
+	Cases []*SelectCase
+}
+
+// SelectCase is a single channel operation of a select expression with the
+// body evaluated when it is chosen. Operation is a recv or send call, or nil
+// for the default case.
+type SelectCase struct {
+	Binding   *Identifier // The name bound to the received value, if any
+	Operation *CallExpression
+	Body      *BlockStatement
+}
+
+func (se *SelectExpression) expressionNode() {}
+
+// TokenLiteral prints the literal value of the token associated with this node
+func (se *SelectExpression) TokenLiteral() string {
+	return se.Token.Literal
+}
+
+// Pos returns the position of the token associated with this node
+func (se *SelectExpression) Pos() token.Position {
+	return se.Token.Position
+}
+
+// String returns a stringified version of the AST for debugging
+func (se *SelectExpression) String() string {
+	var out bytes.Buffer
+	var cases []string
+
+	for _, c := range se.Cases {
+		cases = append(cases, c.String())
+	}
+
+	out.WriteString("select { ")
+	out.WriteString(strings.Join(cases, ", "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+// String returns a stringified version of the AST for debugging
+func (sc *SelectCase) String() string {
+	var out bytes.Buffer
+
+	switch {
+	case sc.Operation == nil:
+		out.WriteString("_")
+	case sc.Binding != nil:
+		out.WriteString(sc.Binding.String() + " = " + sc.Operation.String())
+	default:
+		out.WriteString(sc.Operation.String())
+	}
+
+	out.WriteString(" => ")
+	out.WriteString(sc.Body.String())
+
+	return out.String()
+}
+
+// WhileStatement represents a loop of the form: while (cond) { ... }
+type WhileStatement struct {
+	Token     token.Token // The 'while' token
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (ws *WhileStatement) statementNode() {}
+
+// TokenLiteral prints the literal value of the token associated with this node
+func (ws *WhileStatement) TokenLiteral() string {
+	return ws.Token.Literal
+}
+
+// Pos returns the position of the token associated with this node
+func (ws *WhileStatement) Pos() token.Position {
+	return ws.Token.Position
+}
+
+// String returns a stringified version of the AST for debugging
+func (ws *WhileStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("while")
+	out.WriteString(ws.Condition.String())
+	out.WriteString(" ")
+	out.WriteString(ws.Body.String())
+
+	return out.String()
+}
+
+// ForStatement represents a C-style loop of the form:
+// for (init; cond; post) { ... }
+// Any of the three clauses may be omitted.
+type ForStatement struct {
+	Token     token.Token // The 'for' token
+	Init      Statement
+	Condition Expression
+	Post      Expression
+	Body      *BlockStatement
+}
+
+func (fs *ForStatement) statementNode() {}
+
+// TokenLiteral prints the literal value of the token associated with this node
+func (fs *ForStatement) TokenLiteral() string {
+	return fs.Token.Literal
+}
+
+// Pos returns the position of the token associated with this node
+func (fs *ForStatement) Pos() token.Position {
+	return fs.Token.Position
+}
+
+// String returns a stringified version of the AST for debugging
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for (")
+
+	if fs.Init != nil {
+		out.WriteString(fs.Init.String())
+	}
+
+	out.WriteString("; ")
+
+	if fs.Condition != nil {
+		out.WriteString(fs.Condition.String())
+	}
+
+	out.WriteString("; ")
+
+	if fs.Post != nil {
+		out.WriteString(fs.Post.String())
+	}
+
+	out.WriteString(") ")
+	out.WriteString(fs.Body.String())
+
+	return out.String()
+}
+
+// ForInStatement represents a loop over the items of an iterable of the form:
+// for (v in iterable) { ... } or for (k, v in iterable) { ... }
+// With a single variable hashes bind their keys and every other iterable
+// binds its elements.
+type ForInStatement struct {
+	Token    token.Token // The 'for' token
+	Key      *Identifier // nil unless two variables are given
+	Value    *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fs *ForInStatement) statementNode() {}
+
+// TokenLiteral prints the literal value of the token associated with this node
+func (fs *ForInStatement) TokenLiteral() string {
+	return fs.Token.Literal
+}
+
+// Pos returns the position of the token associated with this node
+func (fs *ForInStatement) Pos() token.Position {
+	return fs.Token.Position
+}
+
+// String returns a stringified version of the AST for debugging
+func (fs *ForInStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for (")
+
+	if fs.Key != nil {
+		out.WriteString(fs.Key.String() + ", ")
+	}
+
+	out.WriteString(fs.Value.String())
+	out.WriteString(" in ")
+	out.WriteString(fs.Iterable.String())
+	out.WriteString(") ")
+	out.WriteString(fs.Body.String())
+
+	return out.String()
+}
+
+// BreakStatement represents a break statement inside of a loop
+type BreakStatement struct {
+	Token token.Token // The 'break' token
+}
+
+func (bs *BreakStatement) statementNode() {}
+
+// TokenLiteral prints the literal value of the token associated with this node
+func (bs *BreakStatement) TokenLiteral() string {
+	return bs.Token.Literal
+}
+
+// Pos returns the position of the token associated with this node
+func (bs *BreakStatement) Pos() token.Position {
+	return bs.Token.Position
+}
+
+// String returns a stringified version of the AST for debugging
+func (bs *BreakStatement) String() string {
+	return bs.TokenLiteral() + ";"
+}
+
+// ContinueStatement represents a continue statement inside of a loop
+type ContinueStatement struct {
+	Token token.Token // The 'continue' token
+}
+
+func (cs *ContinueStatement) statementNode() {}
+
+// TokenLiteral prints the literal value of the token associated with this node
+func (cs *ContinueStatement) TokenLiteral() string {
+	return cs.Token.Literal
+}
+
+// Pos returns the position of the token associated with this node
+func (cs *ContinueStatement) Pos() token.Position {
+	return cs.Token.Position
+}
+
+// String returns a stringified version of the AST for debugging
+func (cs *ContinueStatement) String() string {
+	return cs.TokenLiteral() + ";"
+}
+
 type FunctionLiteral struct {
-	Token      token.Token // The 'fn' token
-	Parameters []*Identifier
+	Token      token.Token  // The 'fn' token
+	Parameters []Expression // Identifiers or array and hash destructuring patterns
+	Defaults   []Expression // The default value of each parameter, nil when it has none
+	Rest       *Identifier  // The ...rest parameter collecting extra arguments, if any
 	Body       *BlockStatement
+	Generator  bool // Set when the body yields, calls then return an iterator
+	// Set when the body refers to arguments, calls then accept extra arguments
+	UsesArguments bool
+	// The identifiers the function and the functions nested in it refer to
+	Names map[string]bool
 }
 
 func (fl *FunctionLiteral) expressionNode() {}
@@ -275,12 +831,25 @@ func (fl *FunctionLiteral) TokenLiteral() string {
 	return fl.Token.Literal
 }
 
+// Pos returns the position of the token associated with this node
+func (fl *FunctionLiteral) Pos() token.Position {
+	return fl.Token.Position
+}
+
 func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
 	var params []string
 
-	for _, p := range fl.Parameters {
-		params = append(params, p.String())
+	for i, p := range fl.Parameters {
+		if i < len(fl.Defaults) && fl.Defaults[i] != nil {
+			params = append(params, p.String()+" = "+fl.Defaults[i].String())
+		} else {
+			params = append(params, p.String())
+		}
+	}
+
+	if fl.Rest != nil {
+		params = append(params, "..."+fl.Rest.String())
 	}
 
 	out.WriteString(fl.TokenLiteral())
@@ -296,6 +865,7 @@ type CallExpression struct {
 	Token     token.Token // The '(' token
 	Function  Expression  // Identifier or FunctionLiteral
 	Arguments []Expression
+	Optional  bool // f?.(x)
 }
 
 func (ce *CallExpression) expressionNode() {}
@@ -304,6 +874,11 @@ func (ce *CallExpression) TokenLiteral() string {
 	return ce.Token.Literal
 }
 
+// Pos returns the position of the called expression rather than the '(' token
+func (ce *CallExpression) Pos() token.Position {
+	return ce.Function.Pos()
+}
+
 func (ce *CallExpression) String() string {
 	var out bytes.Buffer
 	var args []string
@@ -313,6 +888,9 @@ func (ce *CallExpression) String() string {
 	}
 
 	out.WriteString(ce.Function.String())
+	if ce.Optional {
+		out.WriteString("?.")
+	}
 	out.WriteString("(")
 	out.WriteString(strings.Join(args, ", "))
 	out.WriteString(")")
@@ -327,8 +905,48 @@ type StringLiteral struct {
 
 func (sl *StringLiteral) expressionNode()      {}
 func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) Pos() token.Position  { return sl.Token.Position }
 func (sl *StringLiteral) String() string       { return sl.Token.Literal }
 
+// TemplateLiteral represents a backtick string with interpolations e.g.
+// `Hello ${name}!` where Parts alternates between string literals and the
+// interpolated expressions
+type TemplateLiteral struct {
+	Token token.Token // The first TEMPLATE or TEMPLATE_PART token
+	Parts []Expression
+}
+
+func (tl *TemplateLiteral) expressionNode() {}
+
+// TokenLiteral prints the literal value of the token associated with this node
+func (tl *TemplateLiteral) TokenLiteral() string {
+	return tl.Token.Literal
+}
+
+// Pos returns the position of the token associated with this node
+func (tl *TemplateLiteral) Pos() token.Position {
+	return tl.Token.Position
+}
+
+// String returns a stringified version of the AST for debugging
+func (tl *TemplateLiteral) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("`")
+
+	for _, part := range tl.Parts {
+		if str, ok := part.(*StringLiteral); ok {
+			out.WriteString(str.Value)
+		} else {
+			out.WriteString("${" + part.String() + "}")
+		}
+	}
+
+	out.WriteString("`")
+
+	return out.String()
+}
+
 type ArrayLiteral struct {
 	Token    token.Token // the '[' token
 	Elements []Expression
@@ -336,6 +954,7 @@ type ArrayLiteral struct {
 
 func (al *ArrayLiteral) expressionNode()      {}
 func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) Pos() token.Position  { return al.Token.Position }
 func (al *ArrayLiteral) String() string {
 	var out bytes.Buffer
 	var elements []string
@@ -352,9 +971,10 @@ func (al *ArrayLiteral) String() string {
 }
 
 type IndexExpression struct {
-	Token token.Token // The [ token
-	Left  Expression
-	Index Expression
+	Token    token.Token // The [ token
+	Left     Expression
+	Index    Expression
+	Optional bool // a?.[k] or a?.b
 }
 
 func (ie *IndexExpression) expressionNode() {}
@@ -363,11 +983,19 @@ func (ie *IndexExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
 
+// Pos returns the position of the token associated with this node
+func (ie *IndexExpression) Pos() token.Position {
+	return ie.Token.Position
+}
+
 func (ie *IndexExpression) String() string {
 	var out bytes.Buffer
 
 	out.WriteString("(")
 	out.WriteString(ie.Left.String())
+	if ie.Optional {
+		out.WriteString("?.")
+	}
 	out.WriteString("[")
 	out.WriteString(ie.Index.String())
 	out.WriteString("])")
@@ -375,8 +1003,59 @@ func (ie *IndexExpression) String() string {
 	return out.String()
 }
 
+// SliceExpression represents an expression of the form: xs[start:end:step],
+// where any of start, end and step may be omitted and are then nil
+type SliceExpression struct {
+	Token token.Token // The [ token
+	Left  Expression
+	Start Expression
+	End   Expression
+	Step  Expression
+	// Optional is set for xs?.[start:end]
+	Optional bool
+}
+
+func (se *SliceExpression) expressionNode() {}
+
+func (se *SliceExpression) TokenLiteral() string {
+	return se.Token.Literal
+}
+
+// Pos returns the position of the token associated with this node
+func (se *SliceExpression) Pos() token.Position {
+	return se.Token.Position
+}
+
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	if se.Optional {
+		out.WriteString("?.")
+	}
+	out.WriteString("[")
+	if se.Start != nil {
+		out.WriteString(se.Start.String())
+	}
+	out.WriteString(":")
+	if se.End != nil {
+		out.WriteString(se.End.String())
+	}
+	if se.Step != nil {
+		out.WriteString(":")
+		out.WriteString(se.Step.String())
+	}
+	out.WriteString("])")
+
+	return out.String()
+}
+
 type HashLiteral struct {
 	Token token.Token // the '{' token
+	// Keys holds the keys of Pairs in source order. A spread entry such as
+	// ...defaults is stored as a SpreadExpression mapped to itself.
+	Keys  []Expression
 	Pairs map[Expression]Expression
 }
 
@@ -386,12 +1065,22 @@ func (hl *HashLiteral) TokenLiteral() string {
 	return hl.Token.Literal
 }
 
+// Pos returns the position of the token associated with this node
+func (hl *HashLiteral) Pos() token.Position {
+	return hl.Token.Position
+}
+
 func (hl *HashLiteral) String() string {
 	var out bytes.Buffer
 	var pairs []string
 
-	for key, value := range hl.Pairs {
-		pairs = append(pairs, key.String()+":"+value.String())
+	for _, key := range hl.Keys {
+		if _, ok := key.(*SpreadExpression); ok {
+			pairs = append(pairs, key.String())
+			continue
+		}
+
+		pairs = append(pairs, key.String()+":"+hl.Pairs[key].String())
 	}
 
 	out.WriteString("{")
@@ -401,12 +1090,39 @@ func (hl *HashLiteral) String() string {
 	return out.String()
 }
 
+// SpreadExpression represents an expression of the form: ...xs
+// In destructuring patterns it collects the remaining elements.
+type SpreadExpression struct {
+	Token token.Token // The '...' token
+	Value Expression
+}
+
+func (se *SpreadExpression) expressionNode() {}
+
+// TokenLiteral prints the literal value of the token associated with this node
+func (se *SpreadExpression) TokenLiteral() string {
+	return se.Token.Literal
+}
+
+// Pos returns the position of the token associated with this node
+func (se *SpreadExpression) Pos() token.Position {
+	return se.Token.Position
+}
+
+// String returns a stringified version of the AST for debugging
+func (se *SpreadExpression) String() string {
+	return se.TokenLiteral() + se.Value.String()
+}
+
 // AssignmentExpression represents an assignment expression of the form:
-// x = 1 or xs[1] = 2
+// x = 1 or xs[1] = 2, or a compound assignment such as x += 1
+// The left side may also be an array or hash literal used as a destructuring
+// pattern, e.g. [first, ...rest] = xs or {name, roles} = payload
 type AssignmentExpression struct {
-	Token token.Token // The = token
-	Left  Expression
-	Value Expression
+	Token    token.Token // The = token or a compound assignment token e.g. +=
+	Left     Expression
+	Operator string // The infix operator of a compound assignment e.g. +
+	Value    Expression
 }
 
 func (ae *AssignmentExpression) expressionNode() {}
@@ -416,6 +1132,11 @@ func (ae *AssignmentExpression) TokenLiteral() string {
 	return ae.Token.Literal
 }
 
+// Pos returns the position of the token associated with this node
+func (ae *AssignmentExpression) Pos() token.Position {
+	return ae.Token.Position
+}
+
 // String returns a stringified version of the AST for debugging
 func (ae *AssignmentExpression) String() string {
 	var out bytes.Buffer
@@ -437,6 +1158,7 @@ func (c *Comment) statementNode() {}
 
 // TokenLiteral prints the literal value of the token associated with this node
 func (c *Comment) TokenLiteral() string { return c.Token.Literal }
+func (c *Comment) Pos() token.Position  { return c.Token.Position }
 
 // String returns a stringified version of the AST for debugging
 func (c *Comment) String() string {
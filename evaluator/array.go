@@ -1,8 +1,10 @@
 package evaluator
 
 import (
+	"math"
 	"monkey/object"
 	"monkey/typing"
+	"strings"
 )
 
 func init() {
@@ -14,34 +16,35 @@ func init() {
 				typing.RangeOfArgs(2, 3),
 				typing.AllOfType(object.INTEGER_OBJ),
 			); err != nil {
-				return newError("%s", err.Error())
+				return newErrorFrom(err)
 			}
 
 			step := int64(1)
-			end := args[1].(*object.Integer)
-			start := args[0].(*object.Integer)
+			start := args[0].(*object.Integer).Value
+			end := args[1].(*object.Integer).Value
 
 			if len(args) == 3 {
-				s := args[2].(*object.Integer)
-				step = s.Value
+				step = args[2].(*object.Integer).Value
 			}
 
-			i := start.Value
-			var arr []object.Object
+			if step == 0 {
+				return newKindError(typing.ValueError, "range() step cannot be zero")
+			}
 
-			for i < end.Value {
-				arr = append(arr, &object.Integer{Value: i})
-				i = i + step
+			// the values are produced lazily, counting down for negative steps
+			r, ok := object.NewRange(start, end, step)
+			if !ok {
+				return newKindError(typing.ValueError, "range() is too long, it can hold at most %d integers", int64(math.MaxInt64))
 			}
 
-			return &object.Array{Elements: arr}
+			return r
 		},
 	}
 
 	builtins["array_first"] = &object.Builtin{
 		Fn: func(env *object.Environment, args ...object.Object) object.Object {
 			if err := typing.Check("array_first", args, typing.ExactArgs(1), typing.WithTypes(object.ARRAY_OBJ)); err != nil {
-				return newError("%s", err.Error())
+				return newErrorFrom(err)
 			}
 
 			arr := args[0].(*object.Array)
@@ -55,7 +58,7 @@ func init() {
 	builtins["array_last"] = &object.Builtin{
 		Fn: func(env *object.Environment, args ...object.Object) object.Object {
 			if err := typing.Check("array_last", args, typing.ExactArgs(1), typing.WithTypes(object.ARRAY_OBJ)); err != nil {
-				return newError("%s", err.Error())
+				return newErrorFrom(err)
 			}
 
 			arr := args[0].(*object.Array)
@@ -69,7 +72,7 @@ func init() {
 	builtins["array_rest"] = &object.Builtin{
 		Fn: func(env *object.Environment, args ...object.Object) object.Object {
 			if err := typing.Check("array_rest", args, typing.ExactArgs(1), typing.WithTypes(object.ARRAY_OBJ)); err != nil {
-				return newError("%s", err.Error())
+				return newErrorFrom(err)
 			}
 
 			arr := args[0].(*object.Array)
@@ -85,7 +88,7 @@ func init() {
 	builtins["array_push"] = &object.Builtin{
 		Fn: func(env *object.Environment, args ...object.Object) object.Object {
 			if err := typing.Check("array_push", args, typing.ExactArgs(2), typing.WithTypes(object.ARRAY_OBJ)); err != nil {
-				return newError("%s", err.Error())
+				return newErrorFrom(err)
 			}
 
 			arr := args[0].(*object.Array)
@@ -97,19 +100,93 @@ func init() {
 		},
 	}
 
+	builtins["array_join"] = &object.Builtin{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if err := typing.Check(
+				"array_join",
+				args,
+				typing.ExactArgs(2),
+				typing.WithTypes(object.ARRAY_OBJ, object.STRING_OBJ),
+			); err != nil {
+				return newErrorFrom(err)
+			}
+
+			// elements are converted the same way print does
+			elements := args[0].(*object.Array).Elements
+			parts := make([]string, len(elements))
+			for i, element := range elements {
+				parts[i] = element.Inspect()
+			}
+
+			return &object.String{Value: strings.Join(parts, args[1].(*object.String).Value)}
+		},
+	}
+
 	builtins["array_map"] = &object.Builtin{
 		Fn: func(env *object.Environment, args ...object.Object) object.Object {
-			if err := typing.Check("array_map", args, typing.ExactArgs(2), typing.WithTypes(object.ARRAY_OBJ)); err != nil {
-				return newError("%s", err.Error())
+			if err := typing.Check(
+				"array_map",
+				args,
+				typing.ExactArgs(2),
+				typing.ArgumentOfTypes(1, object.ARRAY_OBJ, object.ITERATOR_OBJ, object.RANGE_OBJ),
+			); err != nil {
+				return newErrorFrom(err)
 			}
 			if !isCallable(args[1]) {
-				return newError("second argument to `array_map` must be callable, got %s", args[1].Type())
+				return newKindError(typing.TypeError, "second argument to `array_map` must be callable, got %s", args[1].Type())
 			}
 
-			arr := args[0].(*object.Array)
-			elements := make([]object.Object, len(arr.Elements))
-			for i, element := range arr.Elements {
-				elements[i] = applyFunction(args[1], []object.Object{element, &object.Integer{Value: int64(i)}}, env, "")
+			// iterators are mapped lazily, arrays and ranges into an array
+			if iterator, ok := args[0].(*object.Iterator); ok {
+				return mapIterator(iterator, func(i int64, value object.Object) object.Object {
+					return applyCallback(args[1], []object.Object{value, &object.Integer{Value: i}}, env)
+				})
+			}
+
+			elements := []object.Object{}
+			if err, _ := eachValue(args[0], func(i int64, element object.Object) (object.Object, bool) {
+				result := applyCallback(args[1], []object.Object{element, &object.Integer{Value: i}}, env)
+				elements = append(elements, result)
+
+				return result, isError(result)
+			}); err != nil {
+				return err
+			}
+			return &object.Array{Elements: elements}
+		},
+	}
+
+	builtins["array_filter"] = &object.Builtin{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if err := typing.Check(
+				"array_filter",
+				args,
+				typing.ExactArgs(2),
+				typing.ArgumentOfTypes(1, object.ARRAY_OBJ, object.ITERATOR_OBJ, object.RANGE_OBJ),
+			); err != nil {
+				return newErrorFrom(err)
+			}
+			if !isCallable(args[1]) {
+				return newKindError(typing.TypeError, "second argument to `array_filter` must be callable, got %s", args[1].Type())
+			}
+
+			// iterators are filtered lazily, arrays and ranges into an array
+			if iterator, ok := args[0].(*object.Iterator); ok {
+				return filterIterator(iterator, func(i int64, value object.Object) object.Object {
+					return applyCallback(args[1], []object.Object{value, &object.Integer{Value: i}}, env)
+				})
+			}
+
+			elements := []object.Object{}
+			if err, _ := eachValue(args[0], func(i int64, element object.Object) (object.Object, bool) {
+				keep := applyCallback(args[1], []object.Object{element, &object.Integer{Value: i}}, env)
+				if isTruthy(keep) && !isError(keep) {
+					elements = append(elements, element)
+				}
+
+				return keep, isError(keep)
+			}); err != nil {
+				return err
 			}
 			return &object.Array{Elements: elements}
 		},
@@ -117,15 +194,24 @@ func init() {
 
 	builtins["array_each"] = &object.Builtin{
 		Fn: func(env *object.Environment, args ...object.Object) object.Object {
-			if err := typing.Check("array_each", args, typing.ExactArgs(2), typing.WithTypes(object.ARRAY_OBJ)); err != nil {
-				return newError("%s", err.Error())
+			if err := typing.Check(
+				"array_each",
+				args,
+				typing.ExactArgs(2),
+				typing.ArgumentOfTypes(1, object.ARRAY_OBJ, object.ITERATOR_OBJ, object.RANGE_OBJ),
+			); err != nil {
+				return newErrorFrom(err)
 			}
 			if !isCallable(args[1]) {
-				return newError("second argument to `array_each` must be callable, got %s", args[1].Type())
+				return newKindError(typing.TypeError, "second argument to `array_each` must be callable, got %s", args[1].Type())
 			}
 
-			for i, element := range args[0].(*object.Array).Elements {
-				applyFunction(args[1], []object.Object{element, &object.Integer{Value: int64(i)}}, env, "")
+			if err, _ := eachValue(args[0], func(i int64, element object.Object) (object.Object, bool) {
+				result := applyCallback(args[1], []object.Object{element, &object.Integer{Value: i}}, env)
+
+				return result, isError(result)
+			}); err != nil {
+				return err
 			}
 			return NULL
 		},
@@ -137,22 +223,24 @@ func init() {
 				"array_reduce",
 				args,
 				typing.ExactArgs(3),
-				typing.WithTypes(object.ARRAY_OBJ),
+				typing.ArgumentOfTypes(1, object.ARRAY_OBJ, object.ITERATOR_OBJ, object.RANGE_OBJ),
 			); err != nil {
-				return newError("%s", err.Error())
+				return newErrorFrom(err)
 			}
 
 			if !isCallable(args[1]) {
-				return newError("second argument to `array_reduce` must be callable, got %s",
+				return newKindError(typing.TypeError, "second argument to `array_reduce` must be callable, got %s",
 					args[1].Type())
 			}
 
-			arr := args[0].(*object.Array)
-			elements := arr.Elements
 			acc := args[2]
 
-			for i := range elements {
-				acc = applyFunction(acc, []object.Object{acc, elements[i], &object.Integer{Value: int64(i)}}, env, "")
+			if err, _ := eachValue(args[0], func(i int64, element object.Object) (object.Object, bool) {
+				acc = applyCallback(args[1], []object.Object{acc, element, &object.Integer{Value: i}}, env)
+
+				return acc, isError(acc)
+			}); err != nil {
+				return err
 			}
 
 			return acc
@@ -162,7 +250,7 @@ func init() {
 	builtins["array_copy"] = &object.Builtin{
 		Fn: func(env *object.Environment, args ...object.Object) object.Object {
 			if err := typing.Check("array_copy", args, typing.ExactArgs(1), typing.WithTypes(object.ARRAY_OBJ)); err != nil {
-				return newError("%s", err.Error())
+				return newErrorFrom(err)
 			}
 
 			elements := make([]object.Object, len(args[0].(*object.Array).Elements))
@@ -0,0 +1,336 @@
+package evaluator
+
+import (
+	"monkey/ast"
+	"monkey/object"
+	"monkey/typing"
+	"reflect"
+)
+
+// maxChannelCapacity bounds the buffer of a channel, which is allocated up front
+const maxChannelCapacity = 1 << 24
+
+// implicitNames are the superglobals the evaluator looks up by itself, which
+// spawned functions need even when they don't refer to them
+var implicitNames = map[string]bool{"FILE": true, "STDIN": true, "STDOUT": true}
+
+func init() {
+	builtins["spawn"] = &object.Builtin{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if err := typing.Check("spawn", args, typing.MinimumArgs(1)); err != nil {
+				return newErrorFrom(err)
+			}
+			if !isCallable(args[0]) {
+				return newKindError(typing.TypeError, "first argument to `spawn` must be callable, got %s", args[0].Type())
+			}
+
+			// the function runs on copies of its arguments and of the bindings
+			// it refers to, sharing nothing but channels and resources with
+			// the caller
+			c := newCopier()
+			fn := c.copy(args[0])
+			arguments := make([]object.Object, len(args)-1)
+			for i, arg := range args[1:] {
+				arguments[i] = c.copy(arg)
+			}
+			env = c.cloneEnvironment(env, nil)
+
+			if c.iterator != nil {
+				return newKindError(typing.TypeError, "spawn cannot share an ITERATOR with the spawned function")
+			}
+
+			// the result, or the error the function failed with, can be received
+			// from the returned channel once the function is done
+			done := &object.Channel{Value: make(chan object.Object, 1)}
+
+			go func() {
+				result, ok := copyValue(callFunction(fn, arguments, env, ""))
+				if !ok {
+					result = newKindError(typing.TypeError, "spawned function cannot return an ITERATOR")
+				}
+
+				done.Value <- result
+				close(done.Value)
+			}()
+
+			return done
+		},
+	}
+
+	builtins["channel"] = &object.Builtin{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if err := typing.Check(
+				"channel",
+				args,
+				typing.MaximumArgs(1),
+				typing.WithTypes(object.INTEGER_OBJ),
+			); err != nil {
+				return newErrorFrom(err)
+			}
+
+			// channels are unbuffered unless given a capacity
+			capacity := int64(0)
+			if len(args) == 1 {
+				capacity = args[0].(*object.Integer).Value
+			}
+
+			if capacity < 0 {
+				return newKindError(typing.ValueError, "channel() capacity cannot be negative, got %d", capacity)
+			}
+
+			if capacity > maxChannelCapacity {
+				return newKindError(typing.ArgumentError, "channel() capacity cannot be more than %d, got %d", maxChannelCapacity, capacity)
+			}
+
+			return &object.Channel{Value: make(chan object.Object, capacity)}
+		},
+	}
+
+	builtins["send"] = &object.Builtin{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if err := typing.Check(
+				"send",
+				args,
+				typing.ExactArgs(2),
+				typing.WithTypes(object.CHANNEL_OBJ),
+			); err != nil {
+				return newErrorFrom(err)
+			}
+
+			return sendValue(args[0].(*object.Channel), args[1])
+		},
+	}
+
+	builtins["recv"] = &object.Builtin{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if err := typing.Check(
+				"recv",
+				args,
+				typing.ExactArgs(1),
+				typing.WithTypes(object.CHANNEL_OBJ),
+			); err != nil {
+				return newErrorFrom(err)
+			}
+
+			// a closed channel yields NULL once it is drained
+			value, ok := <-args[0].(*object.Channel).Value
+			if !ok {
+				return NULL
+			}
+
+			return value
+		},
+	}
+}
+
+// sendValue sends a copy of value on channel, so that the receiver doesn't
+// share arrays and hashes with the sender
+func sendValue(channel *object.Channel, value object.Object) (result object.Object) {
+	defer func() {
+		if recover() != nil {
+			result = newError("send on closed channel")
+		}
+	}()
+
+	copied, ok := copyValue(value)
+	if !ok {
+		return newKindError(typing.TypeError, "cannot send an ITERATOR on a channel")
+	}
+
+	channel.Value <- copied
+
+	return NULL
+}
+
+// closeChannel closes channel, closing it twice is an error
+func closeChannel(channel *object.Channel) (result object.Object) {
+	defer func() {
+		if recover() != nil {
+			result = newError("close: channel is already closed")
+		}
+	}()
+
+	close(channel.Value)
+
+	return NULL
+}
+
+// copyValue returns a deep copy of the arrays, hashes and structs in value,
+// and of the bindings the functions in value refer to, so that no two
+// goroutines share them. Other values are immutable or meant to be shared,
+// such as channels. It returns false when value holds an iterator, which can
+// be neither copied nor shared safely.
+func copyValue(value object.Object) (object.Object, bool) {
+	c := newCopier()
+	copied := c.copy(value)
+
+	return copied, c.iterator == nil
+}
+
+// copier keeps track of the values and environments copied so far, as they
+// may contain themselves or each other
+type copier struct {
+	values       map[object.Object]object.Object
+	environments map[*object.Environment]*object.Environment
+	definitions  map[*object.StructType]*object.StructType
+	// iterator is the first iterator met, which is left uncopied
+	iterator *object.Iterator
+}
+
+func newCopier() *copier {
+	return &copier{
+		values:       map[object.Object]object.Object{},
+		environments: map[*object.Environment]*object.Environment{},
+		definitions:  map[*object.StructType]*object.StructType{},
+	}
+}
+
+func (c *copier) copy(value object.Object) object.Object {
+	if copied, ok := c.values[value]; ok {
+		return copied
+	}
+
+	switch value := value.(type) {
+	case *object.Array:
+		array := &object.Array{Elements: make([]object.Object, len(value.Elements))}
+		c.values[value] = array
+
+		for i, element := range value.Elements {
+			array.Elements[i] = c.copy(element)
+		}
+
+		return array
+	case *object.Hash:
+		hash := &object.Hash{Pairs: make(map[object.HashKey]object.HashPair, len(value.Pairs))}
+		c.values[value] = hash
+
+		for key, pair := range value.Pairs {
+			hash.Pairs[key] = object.HashPair{Key: pair.Key, Value: c.copy(pair.Value)}
+		}
+
+		return hash
+	case *object.Struct:
+		instance := &object.Struct{Definition: c.copyDefinition(value.Definition), Fields: make(map[string]object.Object, len(value.Fields))}
+		c.values[value] = instance
+
+		for name, field := range value.Fields {
+			instance.Fields[name] = c.copy(field)
+		}
+
+		return instance
+	case *object.Function:
+		fn := *value
+		c.values[value] = &fn
+
+		fn.Env = c.cloneEnvironment(value.Env, value.Names)
+
+		return &fn
+	case *object.Iterator:
+		if c.iterator == nil {
+			c.iterator = value
+		}
+
+		return value
+	default:
+		return value
+	}
+}
+
+// cloneEnvironment clones the bindings of names in env, along with the
+// superglobals the evaluator looks up by itself
+func (c *copier) cloneEnvironment(env *object.Environment, names map[string]bool) *object.Environment {
+	env.Clone(implicitNames, c.copy, c.environments)
+
+	return env.Clone(names, c.copy, c.environments)
+}
+
+// copyDefinition returns a copy of a struct type whose methods close over
+// copies of their environments
+func (c *copier) copyDefinition(definition *object.StructType) *object.StructType {
+	if copied, ok := c.definitions[definition]; ok {
+		return copied
+	}
+
+	copied := &object.StructType{Name: definition.Name, Fields: definition.Fields, Methods: make(map[string]*object.Function, len(definition.Methods))}
+	c.definitions[definition] = copied
+
+	for name, method := range definition.Methods {
+		copied.Methods[name] = c.copy(method).(*object.Function)
+	}
+
+	return copied
+}
+
+// evalSelectExpression waits until one of the channel operations of a select
+// can proceed, or takes the default case if there is one and none can, and
+// evaluates the body of that case. Cases on a NULL channel never proceed.
+func evalSelectExpression(node *ast.SelectExpression, env *object.Environment) (result object.Object) {
+	cases := make([]reflect.SelectCase, len(node.Cases))
+
+	for i, selectCase := range node.Cases {
+		if selectCase.Operation == nil {
+			cases[i] = reflect.SelectCase{Dir: reflect.SelectDefault}
+			continue
+		}
+
+		channel := Eval(selectCase.Operation.Arguments[0], env)
+		if isError(channel) {
+			return channel
+		}
+
+		var value reflect.Value
+
+		switch channel := channel.(type) {
+		case *object.Channel:
+			value = reflect.ValueOf(channel.Value)
+		case *object.Null:
+		default:
+			return newKindError(typing.TypeError, "select expected a CHANNEL, got %s", channel.Type())
+		}
+
+		if len(selectCase.Operation.Arguments) == 1 {
+			cases[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: value}
+			continue
+		}
+
+		sent := Eval(selectCase.Operation.Arguments[1], env)
+		if isError(sent) {
+			return sent
+		}
+
+		copied, ok := copyValue(sent)
+		if !ok {
+			return newKindError(typing.TypeError, "cannot send an ITERATOR on a channel")
+		}
+
+		cases[i] = reflect.SelectCase{Dir: reflect.SelectSend, Chan: value, Send: reflect.ValueOf(copied)}
+	}
+
+	defer func() {
+		if recover() != nil {
+			result = newError("send on closed channel")
+		}
+	}()
+
+	chosen, received, ok := reflect.Select(cases)
+	selected := node.Cases[chosen]
+	caseEnv := object.NewBlockEnvironment(env)
+
+	if cases[chosen].Dir == reflect.SelectRecv {
+		var value object.Object = NULL
+
+		if ok {
+			value = received.Interface().(object.Object)
+		}
+
+		if isError(value) {
+			return value
+		}
+
+		if selected.Binding != nil {
+			caseEnv.Set(selected.Binding.Value, value, object.BindingOptions{})
+		}
+	}
+
+	return Eval(selected.Body, caseEnv)
+}
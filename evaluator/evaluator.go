@@ -1,14 +1,22 @@
 package evaluator
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"math/big"
 	"monkey/ast"
 	"monkey/lexer"
 	"monkey/object"
 	"monkey/parser"
+	"monkey/token"
 	"monkey/typing"
+	"strings"
 )
 
+// superGlobals and builtins are only written to from init functions, so they
+// can be read without locking from goroutines started by spawn.
 var (
 	// superGlobals is a map that stores predefined super-global variables accessible in the runtime environment.
 	superGlobals = map[string]object.Object{}
@@ -28,14 +36,14 @@ func Run(
 	l := lexer.New(code)
 	p := parser.New(l)
 	program := p.ParseProgram()
-	errors := p.Errors()
+	parserErrors := p.Errors()
 
-	if len(errors) != 0 {
+	if len(parserErrors) != 0 {
 		fmt.Println("Woops! We ran into some monkey business here!")
 		fmt.Println(" parser errors:")
 
-		for _, msg := range errors {
-			fmt.Println("\t" + msg)
+		for _, msg := range parserErrors {
+			fmt.Println("\t" + file + ":" + msg)
 		}
 
 		return nil
@@ -56,6 +64,31 @@ func Run(
 
 // Eval evaluates the AST passed
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	return locateError(eval(node, env), node, env)
+}
+
+// locateError locates an error result at node unless it was raised from an
+// inner node, as are the calls made by builtins and operators, see callFunction
+func locateError(result object.Object, node ast.Node, env *object.Environment) object.Object {
+	if err, ok := result.(*object.Error); ok {
+		if position := node.Pos(); position.Line != 0 {
+			if err.Position.Line == 0 {
+				err.Position = position
+				err.File = currentFile(env)
+			}
+
+			for i := range err.Trace {
+				if err.Trace[i].Position.Line == 0 {
+					err.Trace[i].Position = position
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+func eval(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 	// Statements
 	case *ast.Program:
@@ -64,11 +97,34 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return Eval(node.Expression, env)
 	case *ast.BlockStatement:
 		return evalBlockStatement(node, env)
+	case *ast.LetStatement:
+		return evalLetStatement(node, env)
+	case *ast.WhileStatement:
+		return evalWhileStatement(node, env)
+	case *ast.ForStatement:
+		return evalForStatement(node, env)
+	case *ast.ForInStatement:
+		return evalForInStatement(node, env)
+	case *ast.BreakStatement:
+		return BREAK
+	case *ast.ContinueStatement:
+		return CONTINUE
+	case *ast.ThrowStatement:
+		return evalThrowStatement(node, env)
+	case *ast.YieldStatement:
+		return evalYieldStatement(node, env)
+	case *ast.StructStatement:
+		return evalStructStatement(node, env)
 
 	// Expressions
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
+	case *ast.TemplateLiteral:
+		return evalTemplateLiteral(node, env)
 	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			return &object.BigInt{Value: node.Big}
+		}
 		return &object.Integer{Value: node.Value}
 	case *ast.FloatLiteral:
 		return &object.Float{Value: node.Value}
@@ -83,8 +139,12 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return right
 		}
 
-		return evalPrefixExpression(node.Operator, right)
+		return evalPrefixExpression(node.Operator, right, env)
 	case *ast.InfixExpression:
+		if node.Operator == "&&" || node.Operator == "||" || node.Operator == "??" {
+			return evalLogicalExpression(node, env)
+		}
+
 		left := Eval(node.Left, env)
 
 		if isError(left) {
@@ -97,9 +157,15 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return right
 		}
 
-		return evalInfixExpression(node.Operator, left, right)
+		return evalInfixExpression(node.Operator, left, right, env)
 	case *ast.IfExpression:
 		return evalIfExpression(node, env)
+	case *ast.TryExpression:
+		return evalTryExpression(node, env)
+	case *ast.MatchExpression:
+		return evalMatchExpression(node, env)
+	case *ast.SelectExpression:
+		return evalSelectExpression(node, env)
 	case *ast.ReturnStatement:
 		val := Eval(node.ReturnValue, env)
 
@@ -114,26 +180,20 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		params := node.Parameters
 		body := node.Body
 
-		return &object.Function{Parameters: params, Env: env, Body: body}
-	case *ast.CallExpression:
-		function := Eval(node.Function, env)
-		name := ""
-
-		if ident, ok := node.Function.(*ast.Identifier); ok {
-			name = ident.Value
-		}
-
-		if isError(function) {
-			return function
-		}
-
-		args := evalExpressions(node.Arguments, env)
-
-		if len(args) == 1 && isError(args[0]) {
-			return args[0]
+		return &object.Function{
+			Parameters:    params,
+			Defaults:      node.Defaults,
+			Rest:          node.Rest,
+			Env:           env,
+			Body:          body,
+			Generator:     node.Generator,
+			UsesArguments: node.UsesArguments,
+			Names:         node.Names,
 		}
+	case *ast.CallExpression:
+		result, _ := evalCallExpression(node, env)
 
-		return applyFunction(function, args, env, name)
+		return result
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(node.Elements, env)
 
@@ -145,107 +205,155 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.HashLiteral:
 		return evalHashLiteral(node, env)
 	case *ast.IndexExpression:
-		left := Eval(node.Left, env)
+		result, _ := evalIndexNode(node, env)
 
-		if isError(left) {
-			return left
-		}
+		return result
+	case *ast.SliceExpression:
+		result, _ := evalSliceExpression(node, env)
 
-		index := Eval(node.Index, env)
+		return result
+	case *ast.AssignmentExpression:
+		return evalAssignmentExpression(node, env)
+	case *ast.SpreadExpression:
+		return newError("unexpected %s outside of an array, hash or argument list", node.String())
+	}
 
-		if isError(index) {
-			return index
-		}
+	return nil
+}
 
-		return evalIndexExpression(left, index)
-	case *ast.AssignmentExpression:
-		value := Eval(node.Value, env)
+// evalChainOperand evaluates the operand of an index, slice or call, and
+// reports whether it is a link of the same chain skipped by a ?. further left.
+// Only the operand left of a ?. is checked for NULL, but the rest of the chain
+// is skipped with it, e.g. a?.b.c is NULL when a is but fails when a.b is.
+func evalChainOperand(node ast.Expression, env *object.Environment) (object.Object, bool) {
+	var result object.Object
+	var skipped bool
 
-		if isError(value) {
-			return value
-		}
+	switch node := node.(type) {
+	case *ast.CallExpression:
+		result, skipped = evalCallExpression(node, env)
+	case *ast.IndexExpression:
+		result, skipped = evalIndexNode(node, env)
+	case *ast.SliceExpression:
+		result, skipped = evalSliceExpression(node, env)
+	default:
+		return Eval(node, env), false
+	}
 
-		if ident, ok := node.Left.(*ast.Identifier); ok {
-			if v, ok := env.Get(ident.Value); ok && v.SuperGlobal {
-				return newError("cannot reassign a superglobal")
-			}
+	return locateError(result, node, env), skipped
+}
 
-			if immutable, ok := value.(object.Immutable); ok {
-				env.Set(ident.Value, immutable.Clone(), object.BindingOptions{})
-			} else {
-				env.Set(ident.Value, value, object.BindingOptions{})
-			}
+func evalCallExpression(node *ast.CallExpression, env *object.Environment) (object.Object, bool) {
+	function, skipped := evalChainOperand(node.Function, env)
+	name := callName(node.Function)
 
-			return NULL
-		}
+	if isError(function) {
+		return function, false
+	}
 
-		if ie, ok := node.Left.(*ast.IndexExpression); ok {
-			obj := Eval(ie.Left, env)
+	if skipped || (function == NULL && node.Optional) {
+		return NULL, true
+	}
 
-			if isError(obj) {
-				return obj
-			}
+	args := evalExpressions(node.Arguments, env)
 
-			if array, ok := obj.(*object.Array); ok {
-				index := Eval(ie.Index, env)
+	if len(args) == 1 && isError(args[0]) {
+		return args[0], false
+	}
 
-				if isError(index) {
-					return index
-				}
+	result := applyFunction(function, args, env, name)
 
-				if idx, ok := index.(*object.Integer); ok {
-					if idx.Value < 0 || idx.Value >= int64(len(array.Elements)) {
-						return newError("index out of range: %d", idx.Value)
-					}
-					array.Elements[idx.Value] = value
-				} else {
-					return newError("cannot index array with %#v", index)
-				}
+	if err, ok := result.(*object.Error); ok {
+		err.Trace = append(err.Trace, object.Frame{Function: frameName(name, function), File: currentFile(env), Position: node.Pos()})
+	}
 
-				return NULL
-			}
+	return result, false
+}
 
-			if hash, ok := obj.(*object.Hash); ok {
-				key := Eval(ie.Index, env)
+func evalIndexNode(node *ast.IndexExpression, env *object.Environment) (object.Object, bool) {
+	left, skipped := evalChainOperand(node.Left, env)
 
-				if isError(key) {
-					return key
-				}
+	if isError(left) {
+		return left, false
+	}
 
-				if hashKey, ok := key.(object.Hashable); ok {
-					hashed, err := hashKey.HashKey()
+	if skipped || (left == NULL && node.Optional) {
+		return NULL, true
+	}
 
-					if err != nil {
-						return newError("hash key error: %s", err.Error())
-					}
+	index := Eval(node.Index, env)
 
-					hash.Pairs[hashed] = object.HashPair{Key: key, Value: value}
+	if isError(index) {
+		return index, false
+	}
 
-					return NULL
-				}
+	if isSelector(node) {
+		return evalSelectorExpression(left, index.(*object.String), env), false
+	}
 
-				return newError("cannot index hash with %T", key)
-			}
+	return evalIndexExpression(left, index, env), false
+}
 
-			return newError("object type %s does not support item assignment", obj.Type())
+// callName returns the name a function is called by, e.g. add or math.add, or an empty string for anonymous calls
+func callName(function ast.Expression) string {
+	switch function := function.(type) {
+	case *ast.Identifier:
+		return function.Value
+	case *ast.IndexExpression:
+		if left := callName(function.Left); left != "" && function.Token.Type == token.DOT {
+			return left + "." + function.Index.TokenLiteral()
 		}
+	}
 
-		left := Eval(node.Left, env)
+	return ""
+}
 
-		if isError(left) {
-			return left
-		}
+// frameName returns the name a call of fn is shown with in tracebacks, name
+// is the name it was called by if any
+func frameName(name string, fn object.Object) string {
+	if name != "" {
+		return name
+	}
 
-		return newError("expected identifier or index expression got=%T", left)
+	if builtin, ok := fn.(*object.Builtin); ok && builtin.Name != "" {
+		return builtin.Name
 	}
 
-	return nil
+	return "(anonymous)"
+}
+
+// currentFile returns the file the code evaluated in env belongs to
+func currentFile(env *object.Environment) string {
+	if file, ok := env.Get("FILE"); ok {
+		return file.Value.Inspect()
+	}
+
+	return ""
 }
 
 func newError(format string, a ...any) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
 
+// newKindError creates an error object of the given kind, see the typing package for the known kinds
+func newKindError(kind string, format string, a ...any) *object.Error {
+	return &object.Error{Kind: kind, Message: fmt.Sprintf(format, a...)}
+}
+
+// newErrorFrom converts a Go error into an error object, keeping the kind of errors reported by the typing package
+func newErrorFrom(err error) *object.Error {
+	var typingErr *typing.Error
+
+	if errors.As(err, &typingErr) {
+		result := newKindError(typingErr.Kind, "%s", typingErr.Message)
+		result.Cause = typingErr
+
+		return result
+	}
+
+	return newError("%s", err.Error())
+}
+
 func nativeBoolToBooleanObject(input bool) *object.Boolean {
 	if input {
 		return TRUE
@@ -262,14 +370,14 @@ func isError(obj object.Object) bool {
 	return false
 }
 
+// isTruthy reports whether obj counts as true in conditions, everything but
+// null and false does. Booleans are compared by value, as assignment copies them.
 func isTruthy(obj object.Object) bool {
-	switch obj {
-	case NULL:
-		return false
-	case TRUE:
-		return true
-	case FALSE:
+	switch obj := obj.(type) {
+	case *object.Null:
 		return false
+	case *object.Boolean:
+		return obj.Value
 	default:
 		return true
 	}
@@ -283,218 +391,1176 @@ func applyFunction(fn object.Object, args []object.Object, env *object.Environme
 		}
 
 		parametersLength := len(fn.Parameters)
+		required := 0
+
+		for i, value := range fn.Defaults {
+			if value == nil {
+				required = i + 1
+			}
+		}
+
+		checks := []typing.CheckFunc{typing.MinimumArgs(required)}
+
+		if fn.Rest == nil && !fn.UsesArguments {
+			checks = append(checks, typing.MaximumArgs(parametersLength))
+		}
 
-		if err := typing.Check(
-			name,
-			args,
-			typing.MinimumArgs(parametersLength),
-		); err != nil {
-			return newError("%s", err.Error())
+		if err := typing.Check(name, args, checks...); err != nil {
+			return newErrorFrom(err)
 		}
 
 		extendedEnv := object.NewEnclosedEnvironment(fn.Env)
+		extendedEnv.Set("arguments", &object.Array{Elements: args}, object.BindingOptions{})
 
 		for paramIdx, param := range fn.Parameters {
-			extendedEnv.Set(param.Value, args[paramIdx], object.BindingOptions{})
+			var value object.Object
+
+			if paramIdx < len(args) {
+				value = args[paramIdx]
+			} else {
+				// defaults are evaluated in the callee so they can refer to previous parameters
+				value = Eval(fn.Defaults[paramIdx], extendedEnv)
+
+				if isError(value) {
+					return value
+				}
+			}
+
+			if err := evalDestructuring(param, value, extendedEnv, nil, func(name *ast.Identifier, value object.Object) object.Object {
+				extendedEnv.Set(name.Value, value, object.BindingOptions{})
+
+				return nil
+			}); err != nil {
+				return err
+			}
 		}
 
-		extendedEnv.Set("arguments", &object.Array{Elements: args}, object.BindingOptions{})
+		if fn.Rest != nil {
+			rest := []object.Object{}
+
+			if len(args) > parametersLength {
+				rest = append(rest, args[parametersLength:]...)
+			}
+
+			extendedEnv.Set(fn.Rest.Value, &object.Array{Elements: rest}, object.BindingOptions{})
+		}
+
+		if fn.Generator {
+			return newGenerator(fn, extendedEnv)
+		}
 
 		evaluated := Eval(fn.Body, extendedEnv)
 
-		if returnValue, ok := evaluated.(*object.ReturnValue); ok {
-			return returnValue.Value
+		switch evaluated := evaluated.(type) {
+		case *object.ReturnValue:
+			return evaluated.Value
+		case *object.Break, *object.Continue:
+			return newError("%s outside of a loop", evaluated.Inspect())
 		}
 
 		return evaluated
 	case *object.Builtin:
 		return fn.Fn(env, args...)
 	default:
-		return newError("not a function: %s", fn.Type())
+		return newKindError(typing.TypeError, "not a function: %s", fn.Type())
 	}
 }
 
-func evalIndexExpression(left, index object.Object) object.Object {
-	switch {
-	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
-		return evalStringIndexExpression(left, index)
-	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
-		return evalArrayIndexExpression(left, index)
-	case left.Type() == object.HASH_OBJ:
-		return evalHashIndexExpression(left, index)
-	default:
-		return newError("index operator not supported: %s", left.Type())
+// applyCallback calls a function passed to a builtin, dropping the extra arguments a non-variadic function doesn't accept
+func applyCallback(fn object.Object, args []object.Object, env *object.Environment) object.Object {
+	if function, ok := fn.(*object.Function); ok && function.Rest == nil && !function.UsesArguments && len(args) > len(function.Parameters) {
+		args = args[:len(function.Parameters)]
 	}
+
+	return callFunction(fn, args, env, "")
 }
 
-func evalHashLiteral(
-	node *ast.HashLiteral,
-	env *object.Environment,
-) object.Object {
-	pairs := make(map[object.HashKey]object.HashPair)
+// callFunction applies fn on behalf of a builtin or an operator, adding a
+// frame to the traceback of errors like a call expression does. The position
+// of the frame is that of the node evaluating to the error.
+func callFunction(fn object.Object, args []object.Object, env *object.Environment, name string) object.Object {
+	result := applyFunction(fn, args, env, name)
 
-	for keyNode, valueNode := range node.Pairs {
-		key := Eval(keyNode, env)
-		if isError(key) {
-			return key
+	if err, ok := result.(*object.Error); ok {
+		err.Trace = append(err.Trace, object.Frame{Function: frameName(name, fn), File: currentFile(env)})
+	}
+
+	return result
+}
+
+func evalLetStatement(ls *ast.LetStatement, env *object.Environment) object.Object {
+	name := ls.Name.Value
+
+	if binding, ok := env.Lookup(name); ok {
+		if binding.SuperGlobal {
+			return newError("cannot reassign a superglobal")
 		}
 
-		hashKey, ok := key.(object.Hashable)
-		if !ok {
-			return newError("unusable as hash key: %s", key.Type())
+		if binding.Constant {
+			return newError("cannot redeclare constant %s", name)
 		}
+	}
+
+	value := Eval(ls.Value, env)
+
+	if isError(value) {
+		return value
+	}
+
+	if immutable, ok := value.(object.Immutable); ok {
+		value = immutable.Clone()
+	}
+
+	env.Set(name, value, object.BindingOptions{Constant: ls.Token.Type == token.CONST})
+
+	return NULL
+}
+
+func evalAssignmentExpression(node *ast.AssignmentExpression, env *object.Environment) object.Object {
+	switch left := node.Left.(type) {
+	case *ast.ArrayLiteral, *ast.HashLiteral:
+		value := Eval(node.Value, env)
 
-		value := Eval(valueNode, env)
 		if isError(value) {
 			return value
 		}
 
-		hashed, err := hashKey.HashKey()
-		if err != nil {
-			return newError("hash key error: %s", err.Error())
+		if err := evalDestructuring(left, value, env, func(name *ast.Identifier) object.Object {
+			return checkAssignable(name.Value, env)
+		}, func(name *ast.Identifier, value object.Object) object.Object {
+			return assignIdentifier(name.Value, value, env)
+		}); err != nil {
+			return err
 		}
 
-		pairs[hashed] = object.HashPair{Key: key, Value: value}
-	}
+		return NULL
+	case *ast.Identifier:
+		binding, ok := env.Get(left.Value)
 
-	return &object.Hash{Pairs: pairs}
-}
+		if ok && binding.SuperGlobal {
+			return newError("cannot reassign a superglobal")
+		}
 
-func evalArrayIndexExpression(array, index object.Object) object.Object {
-	arrayObject := array.(*object.Array)
-	idx := index.(*object.Integer).Value
-	if idx < 0 || idx >= int64(len(arrayObject.Elements)) {
-		return NULL
-	}
+		if ok && binding.Constant {
+			return newError("cannot reassign constant %s", left.Value)
+		}
 
-	return arrayObject.Elements[idx]
-}
+		value := Eval(node.Value, env)
 
-func evalHashIndexExpression(hash, index object.Object) object.Object {
-	hashObject := hash.(*object.Hash)
-	key, ok := index.(object.Hashable)
-	if !ok {
-		return newError("unusable as hash key: %s", index.Type())
-	}
+		if isError(value) {
+			return value
+		}
 
-	hashKey, err := key.HashKey()
-	if err != nil {
-		return newError("hash key error: %s", err.Error())
-	}
+		if node.Operator != "" {
+			if !ok {
+				return newError("identifier not found: %s", left.Value)
+			}
+
+			value = evalInfixExpression(node.Operator, binding.Value, value, env)
+
+			if isError(value) {
+				return value
+			}
+		}
+
+		if err := assignIdentifier(left.Value, value, env); err != nil {
+			return err
+		}
 
-	pair, ok := hashObject.Pairs[hashKey]
-	if !ok {
 		return NULL
-	}
+	case *ast.IndexExpression:
+		// The target and index are evaluated exactly once, even for compound
+		// assignments which also need to read the current value.
+		obj := Eval(left.Left, env)
 
-	return pair.Value
-}
+		if isError(obj) {
+			return obj
+		}
 
-func evalStringIndexExpression(str, index object.Object) object.Object {
-	stringObject := str.(*object.String)
-	idx := index.(*object.Integer).Value
-	if idx < 0 || idx >= int64(len(stringObject.Value)) {
-		return &object.String{Value: ""}
-	}
+		index := Eval(left.Index, env)
 
-	return &object.String{Value: string(stringObject.Value[idx])}
-}
+		if isError(index) {
+			return index
+		}
 
-func evalExpressions(
-	exps []ast.Expression,
-	env *object.Environment,
-) []object.Object {
-	var result []object.Object
+		value := Eval(node.Value, env)
 
-	for _, e := range exps {
-		evaluated := Eval(e, env)
+		if isError(value) {
+			return value
+		}
 
-		if isError(evaluated) {
-			return []object.Object{evaluated}
+		if node.Operator != "" {
+			current := evalIndexExpression(obj, index, env)
+
+			if isError(current) {
+				return current
+			}
+
+			value = evalInfixExpression(node.Operator, current, value, env)
+
+			if isError(value) {
+				return value
+			}
 		}
 
-		result = append(result, evaluated)
+		return evalIndexAssignment(obj, index, value)
 	}
 
-	return result
-}
+	left := Eval(node.Left, env)
 
-func evalIdentifier(
-	node *ast.Identifier,
-	env *object.Environment,
-) object.Object {
-	if builtin, ok := builtins[node.Value]; ok {
-		return builtin
+	if isError(left) {
+		return left
 	}
 
-	if val, ok := env.Get(node.Value); ok {
-		return val.Value
+	return newError("expected identifier or index expression got=%T", left)
+}
+
+// checkAssignable returns an error when name is bound to a superglobal or a constant
+func checkAssignable(name string, env *object.Environment) object.Object {
+	if binding, ok := env.Get(name); ok {
+		if binding.SuperGlobal {
+			return newError("cannot reassign a superglobal")
+		}
+
+		if binding.Constant {
+			return newError("cannot reassign constant %s", name)
+		}
 	}
 
-	return newError("identifier not found: %s", node.Value)
+	return nil
 }
 
-func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
-	condition := Eval(ie.Condition, env)
-
-	if isError(condition) {
-		return condition
+// assignIdentifier binds value to the nearest existing binding of name, see object.Environment.Assign
+func assignIdentifier(name string, value object.Object, env *object.Environment) object.Object {
+	if err := checkAssignable(name, env); err != nil {
+		return err
 	}
 
-	if isTruthy(condition) {
-		return Eval(ie.Consequence, env)
-	} else if ie.Alternative != nil {
-		return Eval(ie.Alternative, env)
+	if immutable, ok := value.(object.Immutable); ok {
+		value = immutable.Clone()
 	}
 
-	return NULL
+	env.Assign(name, value, object.BindingOptions{})
+
+	return nil
 }
 
-func evalPrefixExpression(operator string, right object.Object) object.Object {
-	switch operator {
-	case "!":
-		return evalBangOperatorExpression(right)
-	case "-":
-		return evalMinusPrefixOperatorExpression(right)
-	default:
-		return newError("unknown operator: %s%s", operator, right.Type())
+// destructuredTarget is an identifier, or the collection and index of an
+// index expression, of a destructuring pattern with the value it receives
+type destructuredTarget struct {
+	name  *ast.Identifier
+	obj   object.Object
+	index object.Object
+	value object.Object
+}
+
+// evalDestructuring matches value against an identifier, index expression,
+// or array and hash pattern, calling bind for every identifier of the pattern.
+// The whole pattern is matched, and check is called for every identifier when
+// given, before anything is bound so a failing pattern assigns nothing.
+// Missing elements and keys are bound to NULL, extra elements are an error
+// unless collected by a ...rest element. It returns nil on success.
+func evalDestructuring(
+	pattern ast.Expression,
+	value object.Object,
+	env *object.Environment,
+	check func(name *ast.Identifier) object.Object,
+	bind func(name *ast.Identifier, value object.Object) object.Object,
+) object.Object {
+	targets := []destructuredTarget{}
+
+	if err := destructurePattern(pattern, value, env, &targets); err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		if target.name == nil || check == nil {
+			continue
+		}
+
+		if err := check(target.name); err != nil {
+			return err
+		}
+	}
+
+	for _, target := range targets {
+		if target.name == nil {
+			if result := evalIndexAssignment(target.obj, target.index, target.value); isError(result) {
+				return result
+			}
+
+			continue
+		}
+
+		if err := bind(target.name, target.value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// destructurePattern matches value against pattern, appending the targets of the
+// pattern with the values they receive to targets
+func destructurePattern(pattern ast.Expression, value object.Object, env *object.Environment, targets *[]destructuredTarget) object.Object {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		*targets = append(*targets, destructuredTarget{name: pattern, value: value})
+
+		return nil
+	case *ast.IndexExpression:
+		obj := Eval(pattern.Left, env)
+
+		if isError(obj) {
+			return obj
+		}
+
+		index := Eval(pattern.Index, env)
+
+		if isError(index) {
+			return index
+		}
+
+		*targets = append(*targets, destructuredTarget{obj: obj, index: index, value: value})
+
+		return nil
+	case *ast.ArrayLiteral:
+		arr, ok := value.(*object.Array)
+
+		if !ok {
+			return newKindError(typing.TypeError, "cannot destructure %s as an array", value.Type())
+		}
+
+		for i, element := range pattern.Elements {
+			if spread, ok := element.(*ast.SpreadExpression); ok {
+				rest := []object.Object{}
+
+				if i < len(arr.Elements) {
+					rest = append(rest, arr.Elements[i:]...)
+				}
+
+				return destructurePattern(spread.Value, &object.Array{Elements: rest}, env, targets)
+			}
+
+			var item object.Object = NULL
+
+			if i < len(arr.Elements) {
+				item = arr.Elements[i]
+			}
+
+			if err := destructurePattern(element, item, env, targets); err != nil {
+				return err
+			}
+		}
+
+		if len(arr.Elements) > len(pattern.Elements) {
+			return newKindError(
+				typing.ValueError,
+				"too many elements to destructure (expected %d, got %d)",
+				len(pattern.Elements),
+				len(arr.Elements),
+			)
+		}
+
+		return nil
+	case *ast.HashLiteral:
+		hash, ok := value.(*object.Hash)
+
+		if !ok {
+			return newKindError(typing.TypeError, "cannot destructure %s as a hash", value.Type())
+		}
+
+		for keyNode, target := range pattern.Pairs {
+			key := Eval(keyNode, env)
+
+			if isError(key) {
+				return key
+			}
+
+			item := evalHashIndexExpression(hash, key, env)
+
+			if isError(item) {
+				return item
+			}
+
+			if err := destructurePattern(target, item, env, targets); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return newError("invalid destructuring target %s", pattern.String())
+}
+
+func evalIndexAssignment(obj, index, value object.Object) object.Object {
+	switch obj := obj.(type) {
+	case *object.Array:
+		idx, ok := index.(*object.Integer)
+		if !ok {
+			return newKindError(typing.TypeError, "cannot index array with %#v", index)
+		}
+
+		i := idx.Value
+		if i < 0 {
+			i += int64(len(obj.Elements))
+		}
+
+		if i < 0 || i >= int64(len(obj.Elements)) {
+			return newError("index out of range: %d", idx.Value)
+		}
+
+		obj.Elements[i] = value
+
+		return NULL
+	case *object.Hash:
+		hashKey, ok := index.(object.Hashable)
+		if !ok {
+			return newKindError(typing.TypeError, "cannot index hash with %T", index)
+		}
+
+		hashed, err := hashKey.HashKey()
+		if err != nil {
+			return newError("hash key error: %s", err.Error())
+		}
+
+		obj.Pairs[hashed] = object.HashPair{Key: index, Value: value}
+
+		return NULL
+	case *object.Struct:
+		return evalStructFieldAssignment(obj, index, value)
+	default:
+		return newKindError(typing.TypeError, "object type %s does not support item assignment", obj.Type())
+	}
+}
+
+func evalIndexExpression(left, index object.Object, env *object.Environment) object.Object {
+	switch {
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalStringIndexExpression(left, index)
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.RANGE_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalRangeIndexExpression(left, index)
+	case left.Type() == object.HASH_OBJ:
+		return evalHashIndexExpression(left, index, env)
+	case isStruct(left):
+		return evalStructIndexExpression(left.(*object.Struct), index, env)
+	default:
+		return newKindError(typing.TypeError, "index operator not supported: %s", left.Type())
+	}
+}
+
+func evalSliceExpression(node *ast.SliceExpression, env *object.Environment) (object.Object, bool) {
+	left, skipped := evalChainOperand(node.Left, env)
+	if isError(left) {
+		return left, false
+	}
+
+	if skipped || (left == NULL && node.Optional) {
+		return NULL, true
+	}
+
+	return evalSlice(node, left, env), false
+}
+
+// evalSlice slices left with the bounds of node
+func evalSlice(node *ast.SliceExpression, left object.Object, env *object.Environment) object.Object {
+
+	// omitted bounds are NULL, as are bounds explicitly given as null
+	bounds := make([]object.Object, 3)
+	for i, bound := range []ast.Expression{node.Start, node.End, node.Step} {
+		bounds[i] = NULL
+		if bound == nil {
+			continue
+		}
+
+		bounds[i] = Eval(bound, env)
+		if isError(bounds[i]) {
+			return bounds[i]
+		}
+
+		if bounds[i] != NULL && bounds[i].Type() != object.INTEGER_OBJ {
+			return newKindError(typing.TypeError, "slice indices must be integers, got %s", bounds[i].Type())
+		}
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		indices, err := sliceIndices(len(left.Elements), bounds[0], bounds[1], bounds[2])
+		if err != nil {
+			return err
+		}
+
+		elements := make([]object.Object, len(indices))
+		for i, index := range indices {
+			elements[i] = left.Elements[index]
+		}
+
+		return &object.Array{Elements: elements}
+	case *object.String:
+		runes := []rune(left.Value)
+		indices, err := sliceIndices(len(runes), bounds[0], bounds[1], bounds[2])
+		if err != nil {
+			return err
+		}
+
+		sliced := make([]rune, len(indices))
+		for i, index := range indices {
+			sliced[i] = runes[index]
+		}
+
+		return &object.String{Value: string(sliced)}
+	default:
+		return newKindError(typing.TypeError, "slice operator not supported: %s", left.Type())
+	}
+}
+
+// sliceIndices returns the indices selected by slicing length elements from
+// start to end by step. Like Python, negative bounds count from the end and
+// out of range bounds are clamped, while NULL bounds select to the edges.
+func sliceIndices(length int, start, end, step object.Object) ([]int, *object.Error) {
+	stride := 1
+	if step != NULL {
+		stride = int(step.(*object.Integer).Value)
+	}
+
+	if stride == 0 {
+		return nil, newKindError(typing.ValueError, "slice step cannot be zero")
+	}
+
+	first, last := 0, length
+	if stride < 0 {
+		first, last = length-1, -1
+	}
+
+	if start != NULL {
+		first = clampSliceIndex(start.(*object.Integer).Value, length, stride)
+	}
+
+	if end != NULL {
+		last = clampSliceIndex(end.(*object.Integer).Value, length, stride)
+	}
+
+	// the count is computed up front, as stepping past the end of the slice
+	// may overflow for huge steps
+	var distance, magnitude uint64
+	switch {
+	case stride > 0 && first < last:
+		distance, magnitude = uint64(last-first), uint64(stride)
+	case stride < 0 && first > last:
+		distance, magnitude = uint64(first-last), -uint64(stride)
+	default:
+		return nil, nil
+	}
+
+	indices := make([]int, (distance-1)/magnitude+1)
+	for i := range indices {
+		indices[i] = first + i*stride
+	}
+
+	return indices, nil
+}
+
+// clampSliceIndex resolves a negative index and clamps it to the range a
+// slice in the direction of step may start or end at
+func clampSliceIndex(index int64, length, step int) int {
+	if index < 0 {
+		index += int64(length)
+	}
+
+	lower, upper := int64(0), int64(length)
+	if step < 0 {
+		lower, upper = -1, int64(length-1)
+	}
+
+	return int(max(lower, min(index, upper)))
+}
+
+func evalTemplateLiteral(node *ast.TemplateLiteral, env *object.Environment) object.Object {
+	var out strings.Builder
+
+	for _, part := range node.Parts {
+		value := Eval(part, env)
+
+		if isError(value) {
+			return value
+		}
+
+		out.WriteString(value.Inspect())
+	}
+
+	return &object.String{Value: out.String()}
+}
+
+func evalHashLiteral(
+	node *ast.HashLiteral,
+	env *object.Environment,
+) object.Object {
+	pairs := make(map[object.HashKey]object.HashPair)
+
+	for _, keyNode := range node.Keys {
+		if spread, ok := keyNode.(*ast.SpreadExpression); ok {
+			value := Eval(spread.Value, env)
+			if isError(value) {
+				return value
+			}
+
+			hash, ok := value.(*object.Hash)
+			if !ok {
+				return newKindError(typing.TypeError, "cannot spread %s into a hash", value.Type())
+			}
+
+			for hashed, pair := range hash.Pairs {
+				pairs[hashed] = pair
+			}
+			continue
+		}
+
+		valueNode := node.Pairs[keyNode]
+		key := Eval(keyNode, env)
+		if isError(key) {
+			return key
+		}
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newKindError(typing.TypeError, "unusable as hash key: %s", key.Type())
+		}
+
+		value := Eval(valueNode, env)
+		if isError(value) {
+			return value
+		}
+
+		hashed, err := hashKey.HashKey()
+		if err != nil {
+			return newError("hash key error: %s", err.Error())
+		}
+
+		pairs[hashed] = object.HashPair{Key: key, Value: value}
+	}
+
+	return &object.Hash{Pairs: pairs}
+}
+
+func evalArrayIndexExpression(array, index object.Object) object.Object {
+	arrayObject := array.(*object.Array)
+	idx := index.(*object.Integer).Value
+	if idx < 0 {
+		idx += int64(len(arrayObject.Elements))
+	}
+
+	if idx < 0 || idx >= int64(len(arrayObject.Elements)) {
+		return NULL
+	}
+
+	return arrayObject.Elements[idx]
+}
+
+func evalRangeIndexExpression(rangeObject, index object.Object) object.Object {
+	r := rangeObject.(*object.Range)
+	length := r.Len()
+	idx := index.(*object.Integer).Value
+	if idx < 0 {
+		idx += length
+	}
+
+	if idx < 0 || idx >= length {
+		return NULL
+	}
+
+	return &object.Integer{Value: r.At(idx)}
+}
+
+// evalHashIndexExpression returns the value of a key, or the result of the
+// __index metamethod of the hash for missing keys if it has one
+func evalHashIndexExpression(hash, index object.Object, env *object.Environment) object.Object {
+	hashObject := hash.(*object.Hash)
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return newKindError(typing.TypeError, "unusable as hash key: %s", index.Type())
+	}
+
+	hashKey, err := key.HashKey()
+	if err != nil {
+		return newError("hash key error: %s", err.Error())
+	}
+
+	pair, ok := hashObject.Pairs[hashKey]
+	if !ok {
+		if fn, ok := metamethod(hash, "__index"); ok {
+			return callFunction(fn, []object.Object{hash, index}, env, "__index")
+		}
+
+		return NULL
+	}
+
+	return pair.Value
+}
+
+// evalStringIndexExpression returns the character, not the byte, at the given index
+func evalStringIndexExpression(str, index object.Object) object.Object {
+	runes := []rune(str.(*object.String).Value)
+	idx := index.(*object.Integer).Value
+	if idx < 0 {
+		idx += int64(len(runes))
+	}
+
+	if idx < 0 || idx >= int64(len(runes)) {
+		return &object.String{Value: ""}
+	}
+
+	return &object.String{Value: string(runes[idx])}
+}
+
+func evalExpressions(
+	exps []ast.Expression,
+	env *object.Environment,
+) []object.Object {
+	var result []object.Object
+
+	for _, e := range exps {
+		spread, isSpread := e.(*ast.SpreadExpression)
+		if isSpread {
+			e = spread.Value
+		}
+
+		evaluated := Eval(e, env)
+
+		if isError(evaluated) {
+			return []object.Object{evaluated}
+		}
+
+		if isSpread {
+			if _, ok := iteratorOf(evaluated); !ok && evaluated.Type() != object.ARRAY_OBJ {
+				return []object.Object{newKindError(typing.TypeError, "cannot spread %s into an array or arguments", evaluated.Type())}
+			}
+
+			if err, _ := eachValue(evaluated, func(_ int64, value object.Object) (object.Object, bool) {
+				result = append(result, value)
+
+				return nil, false
+			}); err != nil {
+				return []object.Object{err}
+			}
+
+			continue
+		}
+
+		result = append(result, evaluated)
+	}
+
+	return result
+}
+
+func evalIdentifier(
+	node *ast.Identifier,
+	env *object.Environment,
+) object.Object {
+	if builtin, ok := builtins[node.Value]; ok {
+		return builtin
+	}
+
+	if val, ok := env.Get(node.Value); ok {
+		return val.Value
+	}
+
+	return newError("identifier not found: %s", node.Value)
+}
+
+// typePatterns are the identifiers matching values of a type in match expressions
+var typePatterns = map[string]bool{
+	string(object.INTEGER_OBJ):  true,
+	string(object.BIGINT_OBJ):   true,
+	string(object.FLOAT_OBJ):    true,
+	string(object.BOOLEAN_OBJ):  true,
+	string(object.NULL_OBJ):     true,
+	string(object.STRING_OBJ):   true,
+	string(object.ARRAY_OBJ):    true,
+	string(object.HASH_OBJ):     true,
+	string(object.FUNCTION_OBJ): true,
+	string(object.BUILTIN_OBJ):  true,
+	string(object.RESOURCE_OBJ): true,
+	string(object.ITERATOR_OBJ): true,
+	string(object.RANGE_OBJ):    true,
+	string(object.CHANNEL_OBJ):  true,
+}
+
+func evalMatchExpression(me *ast.MatchExpression, env *object.Environment) object.Object {
+	subject := Eval(me.Subject, env)
+
+	if isError(subject) {
+		return subject
+	}
+
+	for _, arm := range me.Arms {
+		armEnv := object.NewBlockEnvironment(env)
+
+		matched, err := matchPattern(arm.Pattern, subject, armEnv)
+
+		if err != nil {
+			return err
+		}
+
+		if !matched {
+			continue
+		}
+
+		if arm.Guard != nil {
+			guard := Eval(arm.Guard, armEnv)
+
+			if isError(guard) {
+				return guard
+			}
+
+			if !isTruthy(guard) {
+				continue
+			}
+		}
+
+		if result := Eval(arm.Body, armEnv); result != nil {
+			return result
+		}
+
+		return NULL
+	}
+
+	return NULL
+}
+
+// matchPattern reports whether value matches pattern, binding the identifiers of the pattern in env
+func matchPattern(pattern ast.Expression, value object.Object, env *object.Environment) (bool, object.Object) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value == "_" {
+			return true, nil
+		}
+
+		if typePatterns[pattern.Value] {
+			return string(value.Type()) == pattern.Value, nil
+		}
+
+		env.Set(pattern.Value, value, object.BindingOptions{})
+
+		return true, nil
+	case *ast.CallExpression:
+		// a type pattern with a nested pattern e.g. STRING(s)
+		if ident, ok := pattern.Function.(*ast.Identifier); ok && len(pattern.Arguments) == 1 {
+			if string(value.Type()) != ident.Value {
+				return false, nil
+			}
+
+			return matchPattern(pattern.Arguments[0], value, env)
+		}
+	case *ast.ArrayLiteral:
+		arr, ok := value.(*object.Array)
+
+		if !ok {
+			return false, nil
+		}
+
+		elements := pattern.Elements
+		var rest *ast.SpreadExpression
+
+		if len(elements) > 0 {
+			rest, _ = elements[len(elements)-1].(*ast.SpreadExpression)
+		}
+
+		if rest != nil {
+			elements = elements[:len(elements)-1]
+
+			if len(arr.Elements) < len(elements) {
+				return false, nil
+			}
+		} else if len(arr.Elements) != len(elements) {
+			return false, nil
+		}
+
+		for i, element := range elements {
+			if matched, err := matchPattern(element, arr.Elements[i], env); !matched || err != nil {
+				return false, err
+			}
+		}
+
+		if rest != nil {
+			remaining := append([]object.Object{}, arr.Elements[len(elements):]...)
+
+			return matchPattern(rest.Value, &object.Array{Elements: remaining}, env)
+		}
+
+		return true, nil
+	case *ast.HashLiteral:
+		hash, ok := value.(*object.Hash)
+
+		if !ok {
+			return false, nil
+		}
+
+		for keyNode, valuePattern := range pattern.Pairs {
+			key := Eval(keyNode, env)
+
+			if isError(key) {
+				return false, key
+			}
+
+			hashable, ok := key.(object.Hashable)
+
+			if !ok {
+				return false, newKindError(typing.TypeError, "unusable as hash key: %s", key.Type())
+			}
+
+			hashKey, err := hashable.HashKey()
+
+			if err != nil {
+				return false, newError("hash key error: %s", err.Error())
+			}
+
+			pair, ok := hash.Pairs[hashKey]
+
+			if !ok {
+				return false, nil
+			}
+
+			if matched, err := matchPattern(valuePattern, pair.Value, env); !matched || err != nil {
+				return false, err
+			}
+		}
+
+		return true, nil
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.TemplateLiteral,
+		*ast.Boolean, *ast.Null, *ast.PrefixExpression:
+		expected := Eval(pattern, env)
+
+		if isError(expected) {
+			return false, expected
+		}
+
+		return objectsEqual(expected, value), nil
+	}
+
+	return false, newError("invalid pattern %s", pattern.String())
+}
+
+// objectsEqual reports whether two values are of the same type and equal
+func objectsEqual(left, right object.Object) bool {
+	switch left := left.(type) {
+	case *object.Integer:
+		right, ok := right.(*object.Integer)
+		return ok && left.Value == right.Value
+	case *object.BigInt:
+		right, ok := right.(*object.BigInt)
+		return ok && left.Value.Cmp(right.Value) == 0
+	case *object.Float:
+		right, ok := right.(*object.Float)
+		return ok && left.Value == right.Value
+	case *object.String:
+		right, ok := right.(*object.String)
+		return ok && left.Value == right.Value
+	case *object.Boolean:
+		right, ok := right.(*object.Boolean)
+		return ok && left.Value == right.Value
+	case *object.Null:
+		return right.Type() == object.NULL_OBJ
+	}
+
+	return left == right
+}
+
+func evalThrowStatement(ts *ast.ThrowStatement, env *object.Environment) object.Object {
+	val := Eval(ts.Value, env)
+
+	if isError(val) {
+		return val
+	}
+
+	err := &object.Error{Message: val.Inspect(), Value: val}
+
+	if hash, ok := val.(*object.Hash); ok {
+		if message, ok := hashGet(hash, "message"); ok {
+			err.Message = message.Inspect()
+		}
+
+		if kind, ok := hashGet(hash, "type"); ok && kind.Inspect() != "Error" {
+			err.Kind = kind.Inspect()
+		}
+	}
+
+	return err
+}
+
+func evalTryExpression(te *ast.TryExpression, env *object.Environment) object.Object {
+	result := Eval(te.Block, object.NewBlockEnvironment(env))
+
+	if err, ok := result.(*object.Error); ok && te.Catch != nil {
+		catchEnv := object.NewBlockEnvironment(env)
+
+		if te.Parameter != nil {
+			catchEnv.Set(te.Parameter.Value, errorToHash(err), object.BindingOptions{})
+		}
+
+		result = Eval(te.Catch, catchEnv)
+	}
+
+	if te.Finally != nil {
+		// an error or a control-flow signal raised by the finally block
+		// replaces whatever the try or catch block produced
+		switch final := Eval(te.Finally, object.NewBlockEnvironment(env)).(type) {
+		case *object.Error, *object.ReturnValue, *object.Break, *object.Continue:
+			return final
+		}
+	}
+
+	if result == nil {
+		return NULL
+	}
+
+	return result
+}
+
+// errorToHash converts an error into the value bound to the parameter of a catch clause
+func errorToHash(err *object.Error) *object.Hash {
+	if hash, ok := err.Value.(*object.Hash); ok {
+		return hash
+	}
+
+	kind := "Error"
+
+	if err.Kind != "" {
+		kind = err.Kind
+	}
+
+	return newErrorHash(err.Message, kind, NULL)
+}
+
+// newErrorHash creates the hash representation of an error
+func newErrorHash(message string, kind string, cause object.Object) *object.Hash {
+	hash := &object.Hash{Pairs: map[object.HashKey]object.HashPair{}}
+
+	hashSet(hash, "message", &object.String{Value: message})
+	hashSet(hash, "type", &object.String{Value: kind})
+	hashSet(hash, "cause", cause)
+
+	return hash
+}
+
+func hashGet(hash *object.Hash, key string) (object.Object, bool) {
+	hashKey, _ := (&object.String{Value: key}).HashKey()
+	pair, ok := hash.Pairs[hashKey]
+
+	return pair.Value, ok
+}
+
+func hashSet(hash *object.Hash, key string, value object.Object) {
+	k := &object.String{Value: key}
+	hashKey, _ := k.HashKey()
+
+	hash.Pairs[hashKey] = object.HashPair{Key: k, Value: value}
+}
+
+func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
+	condition := Eval(ie.Condition, env)
+
+	if isError(condition) {
+		return condition
+	}
+
+	if isTruthy(condition) {
+		return Eval(ie.Consequence, object.NewBlockEnvironment(env))
+	} else if ie.Alternative != nil {
+		return Eval(ie.Alternative, object.NewBlockEnvironment(env))
+	}
+
+	return NULL
+}
+
+func evalPrefixExpression(operator string, right object.Object, env *object.Environment) object.Object {
+	if fn, ok := metamethod(right, "__neg"); ok && operator == "-" {
+		return callFunction(fn, []object.Object{right}, env, "__neg")
+	}
+
+	switch operator {
+	case "!":
+		return evalBangOperatorExpression(right)
+	case "-":
+		return evalMinusPrefixOperatorExpression(right)
+	default:
+		return newKindError(typing.TypeError, "unknown operator: %s%s", operator, right.Type())
 	}
 }
 
 func evalInfixExpression(
 	operator string,
 	left, right object.Object,
+	env *object.Environment,
 ) object.Object {
+	if result, ok := evalMetamethodInfixExpression(operator, left, right, env); ok {
+		return result
+	}
+
 	switch {
 	case isNumeric(left) && isNumeric(right):
 		return evalNumericInfixExpression(operator, left, right)
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right)
 	case operator == "==":
-		return nativeBoolToBooleanObject(left == right)
+		return nativeBoolToBooleanObject(objectsEqual(left, right))
 	case operator == "!=":
-		return nativeBoolToBooleanObject(left != right)
+		return nativeBoolToBooleanObject(!objectsEqual(left, right))
 	case left.Type() != right.Type():
-		return newError("type mismatch: %s %s %s",
+		return newKindError(typing.TypeError, "type mismatch: %s %s %s",
 			left.Type(), operator, right.Type())
 	default:
-		return newError("unknown operator: %s %s %s",
+		return newKindError(typing.TypeError, "unknown operator: %s %s %s",
 			left.Type(), operator, right.Type())
 	}
 }
 
+// evalLogicalExpression short-circuits &&, || and ?? and evaluates to the
+// operand that decided the result rather than a boolean, so `x || "default"`
+// works. ?? only falls back to its right operand when the left one is NULL.
+func evalLogicalExpression(node *ast.InfixExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+
+	if isError(left) {
+		return left
+	}
+
+	switch {
+	case node.Operator == "&&" && !isTruthy(left),
+		node.Operator == "||" && isTruthy(left),
+		node.Operator == "??" && left != NULL:
+		return left
+	}
+
+	return Eval(node.Right, env)
+}
+
+func isStruct(value object.Object) bool {
+	_, ok := value.(*object.Struct)
+	return ok
+}
+
 func isCallable(value object.Object) bool {
 	return value.Type() == object.FUNCTION_OBJ || value.Type() == object.BUILTIN_OBJ
 }
 
 func isNumeric(value object.Object) bool {
-	return value.Type() == object.INTEGER_OBJ || value.Type() == object.FLOAT_OBJ
+	return isIntegral(value) || value.Type() == object.FLOAT_OBJ
+}
+
+func isIntegral(value object.Object) bool {
+	return value.Type() == object.INTEGER_OBJ || value.Type() == object.BIGINT_OBJ
 }
 
 func numericValue(value object.Object) (float64, error) {
 	switch value := value.(type) {
 	case *object.Integer:
 		return float64(value.Value), nil
+	case *object.BigInt:
+		result, _ := new(big.Float).SetInt(value.Value).Float64()
+		return result, nil
 	case *object.Float:
 		return value.Value, nil
 	default:
@@ -502,7 +1568,41 @@ func numericValue(value object.Object) (float64, error) {
 	}
 }
 
+// bigIntValue returns the value of an Integer or BigInt with arbitrary
+// precision
+func bigIntValue(value object.Object) *big.Int {
+	if value, ok := value.(*object.Integer); ok {
+		return big.NewInt(value.Value)
+	}
+
+	return value.(*object.BigInt).Value
+}
+
+// newInteger returns an Integer when value fits in 64 bits and a BigInt
+// otherwise
+func newInteger(value *big.Int) object.Object {
+	if value.IsInt64() {
+		return &object.Integer{Value: value.Int64()}
+	}
+
+	return &object.BigInt{Value: value}
+}
+
 func evalNumericInfixExpression(operator string, left, right object.Object) object.Object {
+	// Integer arithmetic is exact, when 64 bits aren't enough the result is
+	// promoted to a BigInt.
+	leftInteger, leftIsInteger := left.(*object.Integer)
+	rightInteger, rightIsInteger := right.(*object.Integer)
+	if leftIsInteger && rightIsInteger {
+		if result, ok := evalInt64InfixExpression(operator, leftInteger.Value, rightInteger.Value); ok {
+			return result
+		}
+	}
+	if isIntegral(left) && isIntegral(right) {
+		return evalBigIntInfixExpression(operator, left, right)
+	}
+
+	// Anything involving a float is promoted to a float.
 	leftVal, err := numericValue(left)
 	if err != nil {
 		return newError("%s", err)
@@ -512,45 +1612,247 @@ func evalNumericInfixExpression(operator string, left, right object.Object) obje
 		return newError("%s", err)
 	}
 
-	leftInteger, leftIsInteger := left.(*object.Integer)
-	rightInteger, rightIsInteger := right.(*object.Integer)
-	bothIntegers := leftIsInteger && rightIsInteger
-
 	switch operator {
 	case "+":
-		if bothIntegers {
-			return &object.Integer{Value: leftInteger.Value + rightInteger.Value}
-		}
 		return &object.Float{Value: leftVal + rightVal}
 	case "-":
-		if bothIntegers {
-			return &object.Integer{Value: leftInteger.Value - rightInteger.Value}
-		}
 		return &object.Float{Value: leftVal - rightVal}
 	case "*":
-		if bothIntegers {
-			return &object.Integer{Value: leftInteger.Value * rightInteger.Value}
-		}
 		return &object.Float{Value: leftVal * rightVal}
 	case "/":
-		if rightInteger.Value == 0 {
+		if rightVal == 0 {
 			return newError("division by zero")
 		}
-		if bothIntegers && leftInteger.Value%rightInteger.Value == 0 {
-			return &object.Integer{Value: leftInteger.Value / rightInteger.Value}
-		}
 		return &object.Float{Value: leftVal / rightVal}
+	case "//":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		return &object.Float{Value: math.Floor(leftVal / rightVal)}
+	case "%":
+		if rightVal == 0 {
+			return newError("modulo by zero")
+		}
+		modulo := math.Mod(leftVal, rightVal)
+		if modulo != 0 && (modulo < 0) != (rightVal < 0) {
+			modulo += rightVal
+		}
+		return &object.Float{Value: modulo}
+	case "**":
+		return &object.Float{Value: math.Pow(leftVal, rightVal)}
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case ">":
 		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
 	case "==":
 		return nativeBoolToBooleanObject(leftVal == rightVal)
 	case "!=":
 		return nativeBoolToBooleanObject(leftVal != rightVal)
 	default:
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+		return newKindError(typing.TypeError, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalInt64InfixExpression evaluates an operation on two Integers. ok is
+// false when the result overflows 64 bits or the operator isn't supported,
+// leaving it to evalBigIntInfixExpression.
+func evalInt64InfixExpression(operator string, left, right int64) (result object.Object, ok bool) {
+	switch operator {
+	case "+":
+		sum := left + right
+		if (sum > left) != (right > 0) {
+			return nil, false
+		}
+		return &object.Integer{Value: sum}, true
+	case "-":
+		difference := left - right
+		if (difference < left) != (right > 0) {
+			return nil, false
+		}
+		return &object.Integer{Value: difference}, true
+	case "*":
+		product, ok := multiplyInt64(left, right)
+		if !ok {
+			return nil, false
+		}
+		return &object.Integer{Value: product}, true
+	case "/":
+		if right == 0 {
+			return newError("division by zero"), true
+		}
+		if left == math.MinInt64 && right == -1 {
+			return nil, false
+		}
+		if left%right == 0 {
+			return &object.Integer{Value: left / right}, true
+		}
+		return &object.Float{Value: float64(left) / float64(right)}, true
+	case "//":
+		if right == 0 {
+			return newError("division by zero"), true
+		}
+		if left == math.MinInt64 && right == -1 {
+			return nil, false
+		}
+		return &object.Integer{Value: floorDivide(left, right)}, true
+	case "%":
+		if right == 0 {
+			return newError("modulo by zero"), true
+		}
+		return &object.Integer{Value: floorModulo(left, right)}, true
+	case "**":
+		if right < 0 {
+			return &object.Float{Value: math.Pow(float64(left), float64(right))}, true
+		}
+		power, ok := integerPower(left, right)
+		if !ok {
+			return nil, false
+		}
+		return &object.Integer{Value: power}, true
+	case "<":
+		return nativeBoolToBooleanObject(left < right), true
+	case ">":
+		return nativeBoolToBooleanObject(left > right), true
+	case "<=":
+		return nativeBoolToBooleanObject(left <= right), true
+	case ">=":
+		return nativeBoolToBooleanObject(left >= right), true
+	case "==":
+		return nativeBoolToBooleanObject(left == right), true
+	case "!=":
+		return nativeBoolToBooleanObject(left != right), true
+	default:
+		return nil, false
+	}
+}
+
+// evalBigIntInfixExpression evaluates an operation on Integers and BigInts
+// with arbitrary precision
+func evalBigIntInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := bigIntValue(left)
+	rightVal := bigIntValue(right)
+
+	switch operator {
+	case "+":
+		return newInteger(new(big.Int).Add(leftVal, rightVal))
+	case "-":
+		return newInteger(new(big.Int).Sub(leftVal, rightVal))
+	case "*":
+		return newInteger(new(big.Int).Mul(leftVal, rightVal))
+	case "/":
+		if rightVal.Sign() == 0 {
+			return newError("division by zero")
+		}
+		quotient, remainder := new(big.Int).QuoRem(leftVal, rightVal, new(big.Int))
+		if remainder.Sign() == 0 {
+			return newInteger(quotient)
+		}
+		result, _ := new(big.Float).Quo(new(big.Float).SetInt(leftVal), new(big.Float).SetInt(rightVal)).Float64()
+		return &object.Float{Value: result}
+	case "//":
+		if rightVal.Sign() == 0 {
+			return newError("division by zero")
+		}
+		quotient, remainder := new(big.Int).QuoRem(leftVal, rightVal, new(big.Int))
+		if remainder.Sign() != 0 && (remainder.Sign() < 0) != (rightVal.Sign() < 0) {
+			quotient.Sub(quotient, big.NewInt(1))
+		}
+		return newInteger(quotient)
+	case "%":
+		if rightVal.Sign() == 0 {
+			return newError("modulo by zero")
+		}
+		remainder := new(big.Int).Rem(leftVal, rightVal)
+		if remainder.Sign() != 0 && (remainder.Sign() < 0) != (rightVal.Sign() < 0) {
+			remainder.Add(remainder, rightVal)
+		}
+		return newInteger(remainder)
+	case "**":
+		if rightVal.Sign() < 0 {
+			leftFloat, _ := numericValue(left)
+			rightFloat, _ := numericValue(right)
+			return &object.Float{Value: math.Pow(leftFloat, rightFloat)}
+		}
+		if !rightVal.IsInt64() {
+			return newKindError(typing.ValueError, "exponent %s is too large", rightVal)
+		}
+		return newInteger(new(big.Int).Exp(leftVal, rightVal, nil))
+	case "<":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) > 0)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) <= 0)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) >= 0)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) != 0)
+	default:
+		return newKindError(typing.TypeError, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// floorDivide divides rounding towards negative infinity, so that it agrees
+// with floorModulo: a == floorDivide(a, b)*b + floorModulo(a, b)
+func floorDivide(a, b int64) int64 {
+	quotient := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		quotient--
+	}
+
+	return quotient
+}
+
+// floorModulo returns a remainder with the same sign as the divisor
+func floorModulo(a, b int64) int64 {
+	remainder := a % b
+	if remainder != 0 && (remainder < 0) != (b < 0) {
+		remainder += b
+	}
+
+	return remainder
+}
+
+// multiplyInt64 multiplies a and b, ok is false if the product overflows
+func multiplyInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
 	}
+
+	return product, true
+}
+
+// integerPower raises base to a non-negative exponent by repeated squaring,
+// ok is false if the result overflows
+func integerPower(base, exponent int64) (int64, bool) {
+	result := int64(1)
+
+	for exponent > 0 {
+		var ok bool
+		if exponent&1 == 1 {
+			if result, ok = multiplyInt64(result, base); !ok {
+				return 0, false
+			}
+		}
+		exponent >>= 1
+		if exponent > 0 {
+			if base, ok = multiplyInt64(base, base); !ok {
+				return 0, false
+			}
+		}
+	}
+
+	return result, true
 }
 
 func evalStringInfixExpression(
@@ -563,38 +1865,40 @@ func evalStringInfixExpression(
 	switch operator {
 	case "+":
 		return &object.String{Value: leftVal + rightVal}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case ">":
 		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
 	case "==":
 		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
 	default:
-		return newError("unknown operator: %s %s %s",
+		return newKindError(typing.TypeError, "unknown operator: %s %s %s",
 			left.Type(), operator, right.Type())
 	}
 }
 
 func evalBangOperatorExpression(right object.Object) object.Object {
-	switch right {
-	case TRUE:
-		return FALSE
-	case FALSE:
-		return TRUE
-	case NULL:
-		return TRUE
-	default:
-		return FALSE
-	}
+	return nativeBoolToBooleanObject(!isTruthy(right))
 }
 
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
 	if right.Type() == object.FLOAT_OBJ {
 		return &object.Float{Value: -right.(*object.Float).Value}
 	}
-	if right.Type() == object.INTEGER_OBJ {
+	if right.Type() == object.INTEGER_OBJ && right.(*object.Integer).Value != math.MinInt64 {
 		return &object.Integer{Value: -right.(*object.Integer).Value}
 	}
+	if isIntegral(right) {
+		return newInteger(new(big.Int).Neg(bigIntValue(right)))
+	}
 
-	return newError("unknown operator: -%s", right.Type())
+	return newKindError(typing.TypeError, "unknown operator: -%s", right.Type())
 }
 
 func evalProgram(program *ast.Program, env *object.Environment) object.Object {
@@ -608,6 +1912,8 @@ func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 			return result.Value
 		case *object.Error:
 			return result
+		case *object.Break, *object.Continue:
+			return newError("%s outside of a loop", result.Inspect())
 		}
 	}
 
@@ -623,7 +1929,8 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 		if result != nil {
 			rt := result.Type()
 
-			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ ||
+				rt == object.BREAK_OBJ || rt == object.CONTINUE_OBJ {
 				return result
 			}
 		}
@@ -631,3 +1938,162 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 
 	return result
 }
+
+func evalWhileStatement(ws *ast.WhileStatement, env *object.Environment) object.Object {
+	for {
+		condition := Eval(ws.Condition, env)
+
+		if isError(condition) {
+			return condition
+		}
+
+		if !isTruthy(condition) {
+			break
+		}
+
+		if result, done := evalLoopBody(ws.Body, object.NewBlockEnvironment(env)); done {
+			return result
+		}
+	}
+
+	return NULL
+}
+
+func evalForStatement(fs *ast.ForStatement, outer *object.Environment) object.Object {
+	// Declarations in the init clause are scoped to the loop
+	env := object.NewBlockEnvironment(outer)
+
+	if fs.Init != nil {
+		init := Eval(fs.Init, env)
+
+		if isError(init) {
+			return init
+		}
+	}
+
+	for {
+		if fs.Condition != nil {
+			condition := Eval(fs.Condition, env)
+
+			if isError(condition) {
+				return condition
+			}
+
+			if !isTruthy(condition) {
+				break
+			}
+		}
+
+		if result, done := evalLoopBody(fs.Body, object.NewBlockEnvironment(env)); done {
+			return result
+		}
+
+		if fs.Post != nil {
+			post := Eval(fs.Post, env)
+
+			if isError(post) {
+				return post
+			}
+		}
+	}
+
+	return NULL
+}
+
+func evalForInStatement(fs *ast.ForInStatement, env *object.Environment) object.Object {
+	iterable := Eval(fs.Iterable, env)
+
+	if isError(iterable) {
+		return iterable
+	}
+
+	// iterate binds the loop variables in a fresh scope and runs the body. item
+	// is what a single loop variable is bound to.
+	iterate := func(key, value, item object.Object) (object.Object, bool) {
+		iterationEnv := object.NewBlockEnvironment(env)
+
+		if fs.Key != nil {
+			iterationEnv.Set(fs.Key.Value, key, object.BindingOptions{})
+			iterationEnv.Set(fs.Value.Value, value, object.BindingOptions{})
+		} else {
+			iterationEnv.Set(fs.Value.Value, item, object.BindingOptions{})
+		}
+
+		return evalLoopBody(fs.Body, iterationEnv)
+	}
+
+	switch iterable := iterable.(type) {
+	case *object.Array, *object.Iterator, *object.Range:
+		if result, done := eachValue(iterable, func(i int64, value object.Object) (object.Object, bool) {
+			return iterate(&object.Integer{Value: i}, value, value)
+		}); done {
+			return result
+		}
+	case *object.Hash:
+		for _, pair := range iterable.Pairs {
+			if result, done := iterate(pair.Key, pair.Value, pair.Key); done {
+				return result
+			}
+		}
+	case *object.String:
+		for i, r := range []rune(iterable.Value) {
+			char := &object.String{Value: string(r)}
+
+			if result, done := iterate(&object.Integer{Value: int64(i)}, char, char); done {
+				return result
+			}
+		}
+	case *object.Channel:
+		// receive until the channel is closed
+		for i := int64(0); ; i++ {
+			value, ok := <-iterable.Value
+			if !ok {
+				break
+			}
+
+			if result, done := iterate(&object.Integer{Value: i}, value, value); done {
+				return result
+			}
+		}
+	case *object.Resource:
+		reader, ok := iterable.Handle.(io.Reader)
+		if !ok {
+			return newError("for: resource is not readable")
+		}
+
+		for i := int64(0); ; i++ {
+			text, ok, err := readLine(reader)
+			if err != nil {
+				return newError("for: %s", err)
+			}
+			if !ok {
+				break
+			}
+
+			line := &object.String{Value: text}
+
+			if result, done := iterate(&object.Integer{Value: i}, line, line); done {
+				return result
+			}
+		}
+	default:
+		return newKindError(typing.TypeError, "object type %s is not iterable", iterable.Type())
+	}
+
+	return NULL
+}
+
+// evalLoopBody runs a single iteration of a loop body. It reports done when
+// the loop must stop, together with the value the loop should evaluate to.
+func evalLoopBody(body *ast.BlockStatement, env *object.Environment) (object.Object, bool) {
+	result := Eval(body, env)
+
+	switch result := result.(type) {
+	case *object.ReturnValue, *object.Error:
+		return result, true
+	case *object.Break:
+		return NULL, true
+	}
+
+	return nil, false
+}
@@ -1,9 +1,13 @@
 package evaluator
 
 import (
+	"fmt"
 	"monkey/lexer"
 	"monkey/object"
 	"monkey/parser"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -27,6 +31,16 @@ func TestEvalIntegerExpression(t *testing.T) {
 		{"3 * 3 * 3 + 10", 37},
 		{"3 * (3 * 3) + 10", 37},
 		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"7 % 3", 1},
+		{"-7 % 3", 2},
+		{"7 % -3", -2},
+		{"7 // 2", 3},
+		{"-7 // 2", -4},
+		{"2 ** 10", 1024},
+		{"2 ** 3 ** 2", 512},
+		{"-2 ** 2", -4},
+		{"(-2) ** 2", 4},
+		{"2 * 3 ** 2", 18},
 	}
 
 	for _, tt := range tests {
@@ -48,6 +62,85 @@ func TestEvalFractionalIntegerDivision(t *testing.T) {
 	}
 }
 
+func TestBigIntegers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"9223372036854775807 + 1", inspectValue("9223372036854775808")},
+		{"-9223372036854775807 - 2", inspectValue("-9223372036854775809")},
+		{"4294967296 * 4294967296", inspectValue("18446744073709551616")},
+		{"2 ** 100", inspectValue("1267650600228229401496703205376")},
+		{"-(-9223372036854775807 - 1)", inspectValue("9223372036854775808")},
+		{"(-9223372036854775807 - 1) // -1", inspectValue("9223372036854775808")},
+		{"123456789012345678901234567890", inspectValue("123456789012345678901234567890")},
+		{"0xFFFF_FFFF_FFFF_FFFF_FF", inspectValue("4722366482869645213695")},
+		{"2 ** 64 - 2 ** 64", 0},
+		{"(2 ** 64) // 2 ** 60", 16},
+		{"-(2 ** 64) // 3", inspectValue("-6148914691236517206")},
+		{"-(2 ** 64) % 3", 2},
+		{"(2 ** 64) / 2 ** 63", 2},
+		{"(2 ** 64) / 2 ** 66", 0.25},
+		{"2 ** 64 + 0.5", 18446744073709551616.5},
+		{"2 ** 64 > 9223372036854775807", true},
+		{"2 ** 64 < 1.0e30", true},
+		{"2 ** 64 == 2 ** 64", true},
+		{"2 ** 64 == 2 ** 64 + 1", false},
+		{"9007199254740993 == 9007199254740992", false},
+		{"h = {2 ** 64: 1}; h[2 ** 64]", 1},
+		{"type(2 ** 64)", inspectValue("BIGINT")},
+		{`int("123456789012345678901234567890") - 1`, inspectValue("123456789012345678901234567889")},
+		{`int("ff", 16)`, 255},
+		{`int("0x10", 0)`, 16},
+		{`int(" -42 ")`, -42},
+		{"int(-2.9)", -2},
+		{"int(1.0e20)", inspectValue("100000000000000000000")},
+		{"str(2 ** 64, 16)", inspectValue("10000000000000000")},
+		{"json_encode([2 ** 64])", inspectValue("[18446744073709551616]")},
+		{`json_decode("18446744073709551616") - 1`, inspectValue("18446744073709551615")},
+		{`int("12a")`, errorValue(`int() invalid literal for base 10: "12a"`)},
+		{`int("1", 1)`, errorValue("int() base must be 0 or between 2 and 36, got 1")},
+		{`int([])`, errorValue("int() argument must be a string or a number, got `ARRAY`")},
+		{`(2 ** 64) // 0`, errorValue("division by zero")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"10 / 2.5", 4},
+		{"7.5 // 2", 3},
+		{"-7.5 // 2", -4},
+		{"7.5 % 2", 1.5},
+		{"-7.5 % 2", 0.5},
+		{"2 ** -1", 0.5},
+		{"2.0 ** 3", 8},
+		{"4 ** 0.5", 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		result, ok := evaluated.(*object.Float)
+		if !ok {
+			t.Errorf("object is not Float. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if result.Value != tt.expected {
+			t.Errorf("object has wrong value. got=%g, want=%g", result.Value, tt.expected)
+		}
+	}
+}
+
 func TestEvalBooleanExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -72,6 +165,19 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"(1 < 2) == false", false},
 		{"(1 > 2) == true", false},
 		{"(1 > 2) == false", true},
+		{"1 <= 2", true},
+		{"2 <= 2", true},
+		{"3 <= 2", false},
+		{"1 >= 2", false},
+		{"2 >= 2", true},
+		{"1.5 >= 1", true},
+		{"1 <= 0.5", false},
+		{`"a" < "b"`, true},
+		{`"b" < "a"`, false},
+		{`"a" <= "a"`, true},
+		{`"a" >= "b"`, false},
+		{`"a" != "b"`, true},
+		{`"a" != "a"`, false},
 	}
 
 	for _, tt := range tests {
@@ -99,6 +205,105 @@ func TestBangOperator(t *testing.T) {
 	}
 }
 
+func TestLogicalOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"true && true", true},
+		{"true && false", false},
+		{"false && true", false},
+		{"false || true", true},
+		{"false || false", false},
+		{"1 < 2 && 2 < 3", true},
+		{"1 > 2 || 2 < 3", true},
+		{"null || 5", 5},
+		{"0 || 5", 0},
+		{"5 && 6", 6},
+		{`null || "default"`, "default"},
+		{"null && 5", nil},
+		{"false && undefined", false},
+		{"true || undefined", true},
+		{"i = 0; f = fn() { i = 1; true }; false && f(); i", 0},
+		{"null ?? 5", 5},
+		{"false ?? 5", false},
+		{"0 ?? 5", 0},
+		{`null ?? null ?? "default"`, "default"},
+		{"i = 0; f = fn() { i = 1; true }; 5 ?? f(); i", 0},
+		{`x = false; x || "default"`, "default"},
+		{"x = true; x && 5", 5},
+		{"x = false; !x", true},
+		{"x = true; !x", false},
+		{"x = true; x == true", true},
+		{"x = false; x != false", false},
+		{"f = fn(b) { b || 3 }; f(false)", 3},
+		{"done = false; n = 0; while (!done) { n += 1; done = n == 3 }; n", 3},
+		{"x = false; if (x) { 1 } else { 2 }", 2},
+		{`x = json_decode("false"); x || "default"`, "default"},
+		{`h = json_decode("{\"on\": false}"); if (h["on"]) { 1 } else { 2 }`, 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestOptionalChaining(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{`user = {"name": "Ada"}; user?.name`, "Ada"},
+		{`user = null; user?.name`, nil},
+		{`user = null; user?.profile.name`, nil},
+		{`payload = {"user": null}; payload["user"]?.["name"] ?? "anonymous"`, "anonymous"},
+		{`payload = {"user": {"name": "Ada"}}; payload["user"]?.["name"] ?? "anonymous"`, "Ada"},
+		{"xs = null; xs?.[1:]", nil},
+		{"f = null; f?.()", nil},
+		{"f = fn(x) { x * 2 }; f?.(2)", 4},
+		{`lib = {}; lib.double?.(2)`, nil},
+		{"i = 0; f = fn() { i = 1 }; x = null; x?.[f()]; i", 0},
+		{`user = null; user.name`, errorValue("index operator not supported: NULL")},
+		{`user = null; user?.profile.name.first`, nil},
+		{`user = null; user?.greet().name`, nil},
+		{`user = {"profile": null}; user.profile?.name.first`, nil},
+		{"xs = null; xs?.[0][1:]", nil},
+		{"i = 0; f = fn() { i = 1 }; x = null; x?.a[f()]; i", 0},
+		{`user = {"profile": null}; user?.profile.name`, errorValue("index operator not supported: NULL")},
+		{`f = fn() { null }; f?.().name`, errorValue("index operator not supported: NULL")},
+		{"xs = [null]; xs?.[0][1:]", errorValue("slice operator not supported: NULL")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestPipelines(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"double = fn(x) { x * 2 }; 3 |> double", 6},
+		{"sub = fn(a, b) { a - b }; 10 |> sub(3)", 7},
+		{"double = fn(x) { x * 2 }; 1 + 2 |> double |> double", 12},
+		{"lib = {\"inc\": fn(x) { x + 1 }}; 1 |> lib.inc()", 2},
+		{"3 |> fn(x) { x * x }", 9},
+		{
+			"[1, 2, 3, 4] |> array_filter(fn(x) { x % 2 == 0 }) |> array_map(fn(x) { x * 10 }) |> array_reduce(fn(acc, x) { acc + x }, 0)",
+			60,
+		},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
 func TestIfElseExpressions(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -150,6 +355,549 @@ func TestReturnStatements(t *testing.T) {
 	}
 }
 
+func TestLoops(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"i = 0; while (i < 10) { i = i + 1 }; i", 10},
+		{"i = 0; while (false) { i = i + 1 }; i", 0},
+		{"i = 0; while (true) { i = i + 1; if (i == 5) { break } }; i", 5},
+		{"sum = 0; i = 0; while (i < 10) { i = i + 1; if (i > 3) { continue } sum = sum + i }; sum", 6},
+		{"sum = 0; for (i = 0; i < 5; i = i + 1) { sum = sum + i }; sum", 10},
+		{"sum = 0; for (i = 0; i < 10; i = i + 1) { if (i == 2) { continue } if (i == 5) { break } sum = sum + i }; sum", 8},
+		{"i = 0; for (;;) { i = i + 1; if (i == 3) { break } }; i", 3},
+		{"i = 0; for (; i < 4;) { i = i + 1 }; i", 4},
+		{"n = 0; for (i = 0; i < 3; i = i + 1) { for (j = 0; j < 3; j = j + 1) { if (j == 1) { break } n = n + 1 } }; n", 3},
+		{"f = fn() { i = 0; while (true) { i = i + 1; if (i == 7) { return i } } }; f()", 7},
+		{"while (false) { 1 }", nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestForInLoops(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "lines.txt")
+	if err := os.WriteFile(file, []byte("a\r\nbb\nccc"), 0644); err != nil {
+		t.Fatalf("WriteFile() returned error: %v", err)
+	}
+
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"sum = 0; for (x in [1, 2, 3]) { sum = sum + x }; sum", 6},
+		{"sum = 0; for (i, x in [10, 20, 30]) { sum = sum + i }; sum", 3},
+		{"sum = 0; for (x in [1, 2, 3, 4]) { if (x == 2) { continue } if (x == 4) { break } sum = sum + x }; sum", 4},
+		{`sum = 0; for (k in {1: "a", 2: "b"}) { sum = sum + k }; sum`, 3},
+		{`sum = 0; for (k, v in {"a": 1, "b": 2}) { sum = sum + v }; sum`, 3},
+		{`s = ""; for (c in "abc") { s = c + s }; s`, "cba"},
+		{`n = 0; for (i, c in "abc") { n = n + i }; n`, 3},
+		{`n = 0; for (line in open("` + file + `")) { n = n + len(line) }; n`, 6},
+		{`last = 0; for (i, line in open("` + file + `")) { last = i }; last`, 2},
+		{"for (x in [1, 2]) { last = x }; last", 2},
+		{"x = 5; for (x in [1, 2]) { }; x", 5},
+		{"f = fn() { for (x in [1, 2, 3]) { if (x == 2) { return x } } }; f()", 2},
+		{"for (x in []) { x }", nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestGenerators(t *testing.T) {
+	naturals := "naturals = fn() { i = 0; while (true) { yield i; i += 1 } }; "
+
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"g = fn() { yield 1; yield 2 }; xs = [...g()]; len(xs) * 10 + xs[1]", 22},
+		{naturals + "total = 0; for (n in naturals()) { if (n > 4) { break } total += n }; total", 10},
+		{naturals + "it = array_map(naturals(), fn(x) { x * 2 }); next(it); next(it); next(it)", 4},
+		{naturals + "it = array_filter(naturals(), fn(x, i) { i % 3 == 0 }); next(it); next(it)", 3},
+		{"g = fn() { yield 1 }; it = g(); next(it) + next(it, 10)", 11},
+		{"g = fn() { yield }; it = g(); next(it)", nil},
+		{"squares = fn(n) { for (i in range(0, n)) { yield i * i } }; array_reduce(squares(4), fn(a, x) { a + x }, 0)", 14},
+		{"g = fn() { yield 1; return 5; yield 2 }; len([...g()])", 1},
+		{`g = fn() { yield "a"; yield "b" }; last = 0; for (i, v in g()) { last = i }; last`, 1},
+		{"n = 0; g = fn() { n += 1; yield n }; it = g(); n", 0},
+		{
+			"log = 0; g = fn() { try { yield 1; yield 2 } finally { log = 1 } }; for (x in g()) { break }; log",
+			1,
+		},
+		{"sum = 0; array_each(range(0, 4), fn(x) { sum += x }); sum", 6},
+		{"xs = [...range(3, 0, -1)]; xs[0] * 100 + xs[1] * 10 + xs[2]", 321},
+		{"len([...range(0, 10, 3)])", 4},
+		{"len([...range(9223372036854775806, 9223372036854775807, 10)])", 1},
+		{"type(range(0, 3))", "RANGE"},
+		{"g = fn() { yield 1 }; type(g())", "ITERATOR"},
+		{"g = fn() { yield 1; yield 2 }; it = g(); [...it]; len([...it])", 0},
+		{"g = fn() { yield 1; throw \"boom\" }; for (x in g()) { }", errorValue("boom")},
+		{"it = array_map(range(0, 3), fn(x) { x + true }); [...it]", errorValue("type mismatch: INTEGER + BOOLEAN")},
+		{"g = fn() { yield next(it) }; it = g(); next(it)", errorValue("generator is already running")},
+		{"range(0, 3, 0)", errorValue("range() step cannot be zero")},
+		{"array_map(1, fn(x) { x })", errorValue("array_map() expected argument #1 to be `ARRAY` or `ITERATOR` or `RANGE` got `INTEGER`")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestRanges(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"r = range(0, 3); [...r]; len([...r])", 3},
+		{"r = range(0, 4); total = 0; for (x in r) { total += x }; for (x in r) { total += x }; total", 12},
+		{"len(range(0, 10, 3))", 4},
+		{"len(range(3, 0, -1))", 3},
+		{"len(range(0, 0))", 0},
+		{"len(range(0, 3, -1))", 0},
+		{"len(range(-9223372036854775807, 9223372036854775807, 9223372036854775807))", 2},
+		{"range(0, 4).len()", 4},
+		{"range(0, 10, 3)[1]", 3},
+		{"range(10, 0, -3)[-1]", 1},
+		{"range(0, 3)[3]", nil},
+		{"range(0, 3)[-4]", nil},
+		{"len(range(0, 9223372036854775807))", 9223372036854775807},
+		{"range(0, 9223372036854775807)[-1]", 9223372036854775806},
+		{"range(-9223372036854775807, 0)[-1]", -1},
+		{"range(9223372036854775807, 0, -1)[-1]", 1},
+		{"r = range(1, 4); m = r.map(fn(x) { x * 2 }); m[2] + r[0]", 7},
+		{"len(array_map(range(0, 3), fn(x) { x }))", 3},
+		{"type(array_filter(range(0, 3), fn(x) { true }))", "ARRAY"},
+		{"r = range(0, 6); len(r.filter(fn(x) { x % 2 == 0 })) + len(r)", 9},
+		{"range(0, 3).map(fn(x) { x + true })", errorValue("type mismatch: INTEGER + BOOLEAN")},
+		{"range(-9223372036854775807 - 1, 9223372036854775807)", errorValue("range() is too long, it can hold at most 9223372036854775807 integers")},
+		{"json_encode(range(0, 9223372036854775807))", errorValue("json_encode: range of 9223372036854775807 integers is too long to encode, the maximum is 16777216")},
+		{"json_encode(range(0, 3))", "[0,1,2]"},
+		{"json_encode(range(0, 0))", "[]"},
+		{"range(0, 3)", inspectValue("range(0, 3)")},
+		{"range(0, 9, 2)", inspectValue("range(0, 9, 2)")},
+		{`match (range(0, 1)) { RANGE => "range", _ => "other" }`, "range"},
+		{"next(range(0, 3))", errorValue("next() expected argument #1 to be `ITERATOR` got `RANGE`")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"recv(spawn(fn(a, b) { a + b }, 1, 2))", 3},
+		{"ch = channel(1); send(ch, 5); recv(ch)", 5},
+		{"ch = channel(); spawn(fn() { send(ch, 7) }); recv(ch)", 7},
+		{"ch = channel(); close(ch); recv(ch)", nil},
+		{
+			"ch = channel(); spawn(fn() { for (i in range(1, 4)) { send(ch, i) } close(ch) }); total = 0; for (x in ch) { total += x }; total",
+			6,
+		},
+		{"xs = [1]; ch = channel(1); send(ch, xs); xs[0] = 2; recv(ch)[0]", 1},
+		{"xs = [1]; done = spawn(fn(ys) { ys[0] = 2 }, xs); recv(done); xs[0]", 1},
+		{"n = 0; recv(spawn(fn() { n = 1 })); n", 0},
+		{"n = 1; recv(spawn(fn() { n += 1; n }))", 2},
+		{"xs = [1]; f = fn() { xs[0] = 2 }; recv(spawn(fn() { f() })); xs[0]", 1},
+		{"xs = [1]; f = fn() { xs[0] = 2 }; ch = channel(1); send(ch, f); recv(ch)(); xs[0]", 1},
+		{"log = [0]; struct S { v, touch: fn() { log[0] = 1 } }; recv(spawn(fn(s) { s.touch() }, S(1))); log[0]", 0},
+		{"count = fn(n) { if (n == 0) { 0 } else { 1 + count(n - 1) } }; recv(spawn(count, 3))", 3},
+		{
+			"h = {}; fill = fn(v) { for (i in range(0, 100)) { h[i] = v } len(h) }; " +
+				"a = spawn(fill, 1); b = spawn(fill, 2); fill(0); recv(a) + recv(b) + len(h)",
+			300,
+		},
+		{
+			"struct Box { items }; box = Box([]); add = fn() { for (i in range(0, 100)) { box.items = [...box.items, i] } }; " +
+				"a = spawn(add); add(); recv(a); len(box.items)",
+			100,
+		},
+		{
+			"g = fn() { for (i in range(0, 50)) { yield i } }; it = g(); " +
+				"f = fn() { n = 0; for (x in it) { n += 1 }; n }; spawn(f)",
+			errorValue("spawn cannot share an ITERATOR with the spawned function"),
+		},
+		{"g = fn() { yield 1 }; spawn(fn(it) { it }, g())", errorValue("spawn cannot share an ITERATOR with the spawned function")},
+		{"g = fn() { yield 1 }; recv(spawn(fn() { g() }))", errorValue("spawned function cannot return an ITERATOR")},
+		{"g = fn() { yield 1 }; ch = channel(1); send(ch, [g()])", errorValue("cannot send an ITERATOR on a channel")},
+		{"g = fn() { yield 1 }; ch = channel(1); select { send(ch, g()) => 1 }", errorValue("cannot send an ITERATOR on a channel")},
+		{"g = fn() { yield 1 }; it = g(); recv(spawn(fn() { 1 }))", 1},
+		{"n = 0; inc = fn() { n += 1 }; get = fn() { n }; recv(spawn(fn() { inc(); inc(); get() })) + n", 2},
+		{"x = 5; recv(spawn(fn() { f = fn() { x }; f() }))", 5},
+		{"y = 3; recv(spawn(fn(a = y) { a }))", 3},
+		{"ch = channel(1); send(ch, 4); select { v = recv(ch) => v * 2, _ => 0 }", 8},
+		{"ch = channel(); select { v = recv(ch) => v, _ => 9 }", 9},
+		{"ch = channel(1); select { send(ch, 3) => recv(ch), _ => 0 }", 3},
+		{"ch = channel(); close(ch); select { v = recv(ch) => v }", nil},
+		{"select { recv(null) => 1, _ => 2 }", 2},
+		{"type(channel())", "CHANNEL"},
+		{"recv(spawn(fn() { throw \"boom\" }))", errorValue("boom")},
+		{"ch = channel(); close(ch); send(ch, 1)", errorValue("send on closed channel")},
+		{"ch = channel(); close(ch); close(ch)", errorValue("close: channel is already closed")},
+		{"ch = channel(); close(ch); select { send(ch, 1) => 1 }", errorValue("send on closed channel")},
+		{"select { recv(1) => 1 }", errorValue("select expected a CHANNEL, got INTEGER")},
+		{"spawn(1)", errorValue("first argument to `spawn` must be callable, got INTEGER")},
+		{"channel(-1)", errorValue("channel() capacity cannot be negative, got -1")},
+		{"channel(9223372036854775807)", errorValue("channel() capacity cannot be more than 16777216, got 9223372036854775807")},
+		{"close(1)", errorValue("close() expected argument #1 to be `RESOURCE` or `CHANNEL` got `INTEGER`")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestStructs(t *testing.T) {
+	point := "struct Point { x, y, sum: fn() { self.x + self.y }, scale: fn(n) { Point(self.x * n, self.y * n) } }; "
+
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{point + "p = Point(1, 2); p.x * 10 + p.y", 12},
+		{point + "type(Point(1, 2))", "Point"},
+		{point + "str(Point(1, 2))", "Point{x: 1, y: 2}"},
+		{point + "Point(1, 2).sum()", 3},
+		{point + "Point(1, 2).scale(3).sum()", 9},
+		{point + "p = Point(1, 2); p.x = 5; p.y += 1; p.sum()", 8},
+		{point + `Point(1, 2)["y"]`, 2},
+		{point + "sum = Point(1, 2).sum; sum()", 3},
+		{point + "match (Point(4, 2)) { INTEGER(n) => n, Point(p) => p.x, _ => 0 }", 4},
+		{point + "p = Point([1], 2); recv(spawn(fn(q) { q.x[0] = 9 }, p)); p.x[0]", 1},
+		{point + "array_map([Point(1, 1), Point(2, 2)], fn(p) { p.sum() })[1]", 4},
+		{point + "json_encode(Point(1, 2))", `{"x":1,"y":2}`},
+		{"struct Empty {}; type(Empty())", "Empty"},
+		{"struct Counter { n, bump: fn() { self.n += 1; self } }; Counter(0).bump().bump().n", 2},
+		{point + "Point(1)", errorValue("Point() takes exactly 2 argument (1 given)")},
+		{point + "Point(1, 2).z", errorValue("Point has no field or method z")},
+		{point + "p = Point(1, 2); p.sum = 1", errorValue("Point has no field sum")},
+		{point + "Point(1, 2)[0]", errorValue("cannot index Point with INTEGER")},
+		{"struct S { m: fn() { self = 1 } }; S().m()", errorValue("cannot reassign constant self")},
+		{"struct INTEGER { x }", errorValue("cannot declare struct INTEGER, INTEGER is a builtin type")},
+		{"struct ERROR { message }; ERROR(1)", errorValue("cannot declare struct ERROR, ERROR is a builtin type")},
+		{"struct BREAK { x }", errorValue("cannot declare struct BREAK, BREAK is a builtin type")},
+		{"struct CONTINUE { x }", errorValue("cannot declare struct CONTINUE, CONTINUE is a builtin type")},
+		{"struct RETURN_VALUE { value }", errorValue("cannot declare struct RETURN_VALUE, RETURN_VALUE is a builtin type")},
+		{"struct RANGE { x }", errorValue("cannot declare struct RANGE, RANGE is a builtin type")},
+		{"const P = 1; struct P { x }", errorValue("cannot redeclare constant P")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestMethodCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{`"héllo".len()`, 5},
+		{`"a,b,c".split(",")[1]`, "b"},
+		{`" Monkey ".trim().upper()`, "MONKEY"},
+		{`"Monkey".lower()`, "monkey"},
+		{`"monkey".contains("key")`, true},
+		{`"42".int() + 1`, 43},
+		{"xs = [1, 2]; xs.push(3); xs.len()", 3},
+		{"[1, 2, 3].map(fn(x) { x * 2 }).filter(fn(x) { x > 2 }).first()", 4},
+		{"[1, 2, 3].reduce(fn(a, x) { a + x }, 0)", 6},
+		{`[1, "a", true].join("-")`, "1-a-true"},
+		{"[3, 4].last()", 4},
+		{"range(0, 4).map(fn(x) { x * x }).reduce(fn(a, x) { a + x }, 0)", 14},
+		{"n = 12; n.str()", "12"},
+		{"f = 2.5; f.int()", 2},
+		{`{"a": 1, "b": 2}.len()`, 2},
+		{`{"a": 1}.keys()[0]`, "a"},
+		{`{"a": 1}.values()[0]`, 1},
+		{`h = {"len": fn() { 10 }}; h.len()`, 10},
+		{`{"a": 1}.b`, nil},
+		{"ch = channel(1); ch.send(5); ch.recv()", 5},
+		{"push = [1].push; push(2)", nil},
+		{"x = [1]; x?.len()", 1},
+		{"x = null; x?.len()", nil},
+		{`"abc".foo()`, errorValue("STRING has no method foo")},
+		{`{"len": 5}.len`, 5},
+		{`{"keys": [1]}.keys[0]`, 1},
+		{"[].push()", errorValue("ARRAY.push() takes exactly 1 argument (0 given)")},
+		{"[1].join()", errorValue("ARRAY.join() takes exactly 1 argument (0 given)")},
+		{`"a".split(1)`, errorValue("STRING.split() expected argument #1 to be `STRING` got `INTEGER`")},
+		{"[1].map(fn(x) { len() })", errorValue("len() takes exactly 1 argument (0 given)")},
+		{"[1].map(1, 2)", errorValue("ARRAY.map() takes exactly 1 argument (2 given)")},
+		{"true.len()", errorValue("index operator not supported: BOOLEAN")},
+		{"x = 1.5; x.int(16)", errorValue("FLOAT has no method int taking 1 argument")},
+		{"n = 255; n.str(1)", errorValue("INTEGER.str() base must be between 2 and 36, got 1")},
+		{`"12".int(40)`, errorValue("STRING.int() base must be 0 or between 2 and 36, got 40")},
+		{`"zz".int()`, errorValue(`STRING.int() invalid literal for base 10: "zz"`)},
+		{"ch = channel(); ch.close(); ch.close()", errorValue("CHANNEL.close: channel is already closed")},
+		{"str(255, 1)", errorValue("str() base must be between 2 and 36, got 1")},
+		{"int(1.5, 16)", errorValue("int() expected argument #1 to be `STRING` got `FLOAT`")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestMetamethods(t *testing.T) {
+	vec := "struct Vec { x, y, " +
+		"__add: fn(a, b) { Vec(a.x + b.x, a.y + b.y) }, " +
+		"__mul: fn(a, b) { match (b) { INTEGER(n) => Vec(a.x * n, a.y * n), _ => a.x * b.x + a.y * b.y } }, " +
+		"__neg: fn(v) { Vec(-v.x, -v.y) }, " +
+		"__eq: fn(a, b) { a.x == b.x && a.y == b.y }, " +
+		"__lt: fn(a, b) { a.x * a.x + a.y * a.y < b.x * b.x + b.y * b.y }, " +
+		"__index: fn(v, i) { [v.x, v.y][i] }, " +
+		"__str: fn(v) { `<${v.x}, ${v.y}>` } }; "
+	money := `money = fn(cents) { {"cents": cents, "__add": fn(a, b) { money(a.cents + b.cents) }, ` +
+		`"__le": fn(a, b) { a.cents <= b.cents }, "__str": fn(m) { "$" + str(m.cents / 100) } } }; `
+
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{vec + "v = Vec(1, 2) + Vec(3, 4); v.x * 10 + v.y", 46},
+		{vec + "(Vec(1, 2) * 3).y", 6},
+		{vec + "Vec(1, 2) * Vec(3, 4)", 11},
+		{vec + "(-Vec(1, 2)).x", -1},
+		{vec + "Vec(1, 2) == Vec(1, 2)", true},
+		{vec + "Vec(1, 2) != Vec(1, 2)", false},
+		{vec + "Vec(1, 2) == 1", false},
+		{vec + "Vec(1, 2) < Vec(3, 4)", true},
+		{vec + "Vec(1, 2) > Vec(3, 4)", false},
+		{vec + "Vec(5, 6)[1]", 6},
+		{vec + "str(Vec(1, 2))", "<1, 2>"},
+		{vec + "`v = ${Vec(3, 4)}`", "v = <3, 4>"},
+		{vec + "v = Vec(1, 1); v += Vec(1, 1); v.x", 2},
+		{money + "str(money(150) + money(250))", "$4"},
+		{money + "money(100) >= money(50)", true},
+		{money + "money(100) <= money(50)", false},
+		{`h = {"__index": fn(h, k) { k * 2 }, "a": 1}; h["a"] + h[5]`, 11},
+		{`h = {"__index": fn(h, k) { "missing " + k }}; h.b`, "missing b"},
+		{`h = {"__add": 1}; h + h`, errorValue("unknown operator: HASH + HASH")},
+		{vec + "Vec(1, 2) - Vec(1, 2)", errorValue("unknown operator: Vec - Vec")},
+		{vec + "Vec(1, 2) < 1", errorValue("INTEGER has no method x")},
+		{"struct P { x }; P(1) + P(2)", errorValue("unknown operator: P + P")},
+		{`e = {"__eq": fn(a, b) { 5 }}; e == {}`, true},
+		{`e = {"__eq": fn(a, b) { 0 }}; e != {}`, false},
+		{`e = {"__eq": fn(a, b) { null }}; e != {}`, true},
+		{"struct N { v, __lt: fn(a, b) { if (a.v < b.v) { 1 } } }; N(1) < N(2)", true},
+		{"struct N { v, __lt: fn(a, b) { if (a.v < b.v) { 1 } } }; N(2) > N(2)", false},
+		{"struct N { v, __le: fn(a, b) { \"yes\" } }; N(1) <= N(2)", true},
+		{`h = {"__str": fn(v) { 1 / 0 }}; str(h) == str({"__str": h["__str"]})`, true},
+		{"struct P { x, __str: fn() { 1 / 0 } }; str(P(1))", "P{x: 1}"},
+		{"struct P { x, __str: fn() { 1 / 0 } }; `${P(1)}`", "P{x: 1}"},
+		{"struct P { x, __str: fn() { 1 / 0 } }; [P(1)]", inspectValue("[P{x: 1}]")},
+		{`h = {"__str": fn(s) { s }}; str(h) == str({"__str": h["__str"]})`, true},
+		{"struct P { x, __str: fn(p) { 42 } }; str(P(1))", "P{x: 1}"},
+		{`struct P { x, __str: fn(p) { "<" + str(p) + ">" } }; str(P(1))`, "<P{x: 1}>"},
+		{`struct P { x, __str: fn(p) { "<" + str(p) + ">" } }; [P(1)]`, inspectValue("[<P{x: 1}>]")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestCompoundAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"counter = 1; counter += 1; counter", 2},
+		{"counter = 5; counter -= 2; counter", 3},
+		{"counter = 5; counter *= 2; counter", 10},
+		{"counter = 10; counter /= 4; counter", 2.5},
+		{"counter = 10; counter %= 4; counter", 2},
+		{`s = "foo"; s += "bar"; s`, "foobar"},
+		{`hash = {"hits": 1}; hash["hits"] += 1; hash["hits"]`, 2},
+		{`hash = {"hits": 1}; hash.hits += 2; hash.hits`, 3},
+		{"arr = [1, 2, 3]; i = 1; arr[i] *= 2; arr[i]", 4},
+		{"calls = [0]; idx = fn() { calls[0] += 1; 0 }; arr = [5]; arr[idx()] += 1; calls[0]", 1},
+		{"calls = [0]; arr = [[1]]; get = fn() { calls[0] += 1; arr }; get()[0][0] += 1; calls[0] * 10 + arr[0][0]", 12},
+		{"total = 0; for (x in [1, 2, 3]) { total += x }; total", 6},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestDeclarations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"let x = 5; x", 5},
+		{"const x = 5; x", 5},
+		{"let x = 5; let x = 6; x", 6},
+		{"x = 1; if (true) { let x = 2 }; x", 1},
+		{"x = 1; if (true) { x = 2 }; x", 2},
+		{"if (true) { y = 2 }; y", 2},
+		{"x = 1; if (true) { let x = 2; x = 3 }; x", 1},
+		{"x = 1; while (x < 3) { let y = x; x += 1 }; x", 3},
+		{"sum = 0; for (let i = 0; i < 3; i += 1) { sum += i }; sum", 3},
+		{"i = 10; for (let i = 0; i < 3; i += 1) { }; i", 10},
+		{"count = 0; inc = fn() { count += 1 }; inc(); inc(); count", 2},
+		{"count = 0; inc = fn() { let count = 5; count += 1 }; inc(); count", 0},
+		{"f = fn() { tmp = 1 }; f(); tmp", errorValue("identifier not found: tmp")},
+		{"makeCounter = fn() { let n = 0; fn() { n += 1; n } }; c = makeCounter(); c(); c()", 2},
+		{"const x = 1; f = fn() { let x = 2; x }; f()", 2},
+		{"const x = 1; x = 2", errorValue("cannot reassign constant x")},
+		{"const x = 1; x += 2", errorValue("cannot reassign constant x")},
+		{"const x = 1; f = fn() { x = 2 }; f()", errorValue("cannot reassign constant x")},
+		{"const x = 1; let x = 2", errorValue("cannot redeclare constant x")},
+		{"const xs = [1]; xs[0] = 2; xs[0]", 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestTryCatch(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"try { 1 } catch (e) { 2 }", 1},
+		{"try { throw 1 } catch (e) { 2 }", 2},
+		{"try { throw \"boom\" } catch (e) { e[\"message\"] }", "boom"},
+		{"try { throw \"boom\" } catch (e) { e[\"type\"] }", "Error"},
+		{"try { 1 + true } catch (e) { e[\"message\"] }", "type mismatch: INTEGER + BOOLEAN"},
+		{"try { 1 + true } catch (e) { e[\"type\"] }", "TypeError"},
+		{"try { len(1, 2) } catch (e) { e[\"type\"] }", "ArgumentError"},
+		{"try { len(1, 2) } catch (e) { e[\"message\"] }", "len() takes exactly 1 argument (2 given)"},
+		{"try { len(1) } catch (e) { e[\"type\"] }", "TypeError"},
+		{"try { throw error(\"bad\") } catch (e) { e[\"message\"] }", "bad"},
+		{"try { throw error(\"bad\", 42) } catch (e) { e[\"cause\"] }", 42},
+		{"try { throw {\"code\": 7} } catch (e) { e[\"code\"] }", 7},
+		{"try { f = fn() { throw \"inner\" }; f() } catch (e) { e[\"message\"] }", "inner"},
+		{"try { throw 1 } catch { 3 }", 3},
+		{"x = 0; try { x = 1 } finally { x = 2 }; x", 2},
+		{"x = 0; try { throw 1 } catch (e) { x += 1 } finally { x += 10 }; x", 11},
+		{"try { 1 } finally { 2 }", 1},
+		{"f = fn() { try { return 1 } finally { return 2 } }; f()", 2},
+		{"i = 0; while (true) { try { break } finally { i = 5 } }; i", 5},
+		{"try { throw 1 } catch (e) { let x = 1 }; x", errorValue("identifier not found: x")},
+		{"try { try { throw \"a\" } finally { 1 } } catch (e) { e[\"message\"] }", "a"},
+		{"try { try { throw \"a\" } catch (e) { throw \"b\" } } catch (e) { e[\"message\"] }", "b"},
+		{"try { throw \"a\" } finally { 1 }", errorValue("a")},
+		{"throw {\"message\": \"invalid\", \"type\": \"ValidationError\"}", errorValue("invalid")},
+		{"try { throw 1 } catch (e) { 1 } finally { throw \"final\" }", errorValue("final")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+// errorValue is the expected message of an uncaught error
+type errorValue string
+
+// inspectValue is the expected Inspect() output of a value in table tests
+type inspectValue string
+
+func TestMatchExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"match (1) { 1 => \"one\", 2 => \"two\" }", "one"},
+		{"match (2) { 1 => \"one\", 2 => \"two\" }", "two"},
+		{"match (3) { 1 => \"one\", _ => \"other\" }", "other"},
+		{"match (3) { 1 => \"one\" }", nil},
+		{"match (-1) { -1 => \"minus one\", _ => \"other\" }", "minus one"},
+		{"match (\"a\") { \"a\" => 1, _ => 2 }", 1},
+		{"match (1.5) { 1.5 => 1, _ => 2 }", 1},
+		{"match (null) { null => 1, _ => 2 }", 1},
+		{"match (true) { false => 1, true => 2 }", 2},
+		{"match (1) { \"1\" => 1, _ => 2 }", 2},
+		{"match (\"hi\") { INTEGER => \"int\", STRING => \"string\" }", "string"},
+		{"match (5) { STRING(s) => s, INTEGER(n) => n * 2 }", 10},
+		{"match (5) { n if n > 10 => \"big\", n => \"small\" }", "small"},
+		{"match (50) { n if n > 10 => \"big\", n => \"small\" }", "big"},
+		{"match ([1, 2]) { [] => 0, [a] => a, [a, b] => a + b }", 3},
+		{"match ([1, 2]) { [1, x] => x, _ => 0 }", 2},
+		{"match ([1, 2, 3]) { [first, ...rest] => len(rest) }", 2},
+		{"match ([1, 2, 3]) { [a, b] => 0, _ => 1 }", 1},
+		{"match ({\"type\": \"user\", \"name\": \"Ada\"}) { {\"type\": \"admin\"} => \"admin\", {\"type\": \"user\", name} => name }", "Ada"},
+		{"match ({\"a\": 1}) { {b} => b, _ => \"no b\" }", "no b"},
+		{"match ({\"point\": [1, 2]}) { {\"point\": [x, y]} => x + y }", 3},
+		{"match (1) { x => { y = x + 1; y * 2 } }", 4},
+		{"match (1) { x => x }; x", errorValue("identifier not found: x")},
+		{"y = 0; match (1) { x => { y = x } }; y", 1},
+		{"match (1) { f(1, 2) => 1 }", errorValue("invalid pattern f(1, 2)")},
+		{"match (missing) { _ => 1 }", errorValue("identifier not found: missing")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestSuperGlobalReassignment(t *testing.T) {
+	tests := []string{
+		"GLOBAL = 2",
+		"GLOBAL += 2",
+		"let GLOBAL = 2",
+		"f = fn() { GLOBAL *= 2 }; f()",
+	}
+
+	for _, input := range tests {
+		env := object.NewEnvironment()
+		env.Set("GLOBAL", &object.Integer{Value: 1}, object.BindingOptions{SuperGlobal: true})
+
+		program := parser.New(lexer.New(input)).ParseProgram()
+		evaluated := Eval(program, env)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != "cannot reassign a superglobal" {
+			t.Errorf("wrong error message. got=%q", errObj.Message)
+		}
+	}
+}
+
 func TestErrorHandling(t *testing.T) {
 	tests := []struct {
 		input           string
@@ -201,6 +949,50 @@ return 1;
 			`{"name": "Monkey"}[fn(x) { x }];`,
 			"unusable as hash key: FUNCTION",
 		},
+		{
+			"5 // 0",
+			"division by zero",
+		},
+		{
+			"5 / 0.0",
+			"division by zero",
+		},
+		{
+			"5 % 0",
+			"modulo by zero",
+		},
+		{
+			`"a" <= 1`,
+			"type mismatch: STRING <= INTEGER",
+		},
+		{
+			"true ** false",
+			"unknown operator: BOOLEAN ** BOOLEAN",
+		},
+		{
+			"undefined += 1",
+			"identifier not found: undefined",
+		},
+		{
+			`x = 1; x += "a"`,
+			"type mismatch: INTEGER + STRING",
+		},
+		{
+			"break;",
+			"break outside of a loop",
+		},
+		{
+			"while (true) { fn() { continue }() }",
+			"continue outside of a loop",
+		},
+		{
+			"while (true) { 1 + true }",
+			"type mismatch: INTEGER + BOOLEAN",
+		},
+		{
+			"for (x in 5) { x }",
+			"object type INTEGER is not iterable",
+		},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
@@ -217,6 +1009,143 @@ return 1;
 	}
 }
 
+func TestErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 + true", "ERROR: test.monkey:1:3: TypeError: type mismatch: INTEGER + BOOLEAN"},
+		{"x = 1;\n  -true", "ERROR: test.monkey:2:3: TypeError: unknown operator: -BOOLEAN"},
+		{"f = fn() {\n  foobar\n};\nf()", "ERROR: test.monkey:2:3: identifier not found: foobar"},
+		{"len(1, 2)", "ERROR: test.monkey:1:1: ArgumentError: len() takes exactly 1 argument (2 given)"},
+		{"if (true) {\n\tthrow \"boom\"\n}", "ERROR: test.monkey:2:2: boom"},
+		{`throw {"message": "invalid", "type": "ValidationError"}`, "ERROR: test.monkey:1:1: ValidationError: invalid"},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		env := object.NewEnvironment()
+		env.Set("FILE", &object.String{Value: "test.monkey"}, object.BindingOptions{SuperGlobal: true})
+
+		evaluated := Eval(program, env)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Inspect() != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errObj.Inspect())
+		}
+	}
+}
+
+func TestStackTraces(t *testing.T) {
+	input := `inner = fn(x) {
+  x + true
+};
+outer = fn() {
+  y = 1;
+  inner(1)
+};
+outer();`
+
+	program := parser.New(lexer.New(input)).ParseProgram()
+	env := object.NewEnvironment()
+	env.Set("FILE", &object.String{Value: "test.monkey"}, object.BindingOptions{SuperGlobal: true})
+
+	evaluated := Eval(program, env)
+
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	expected := `ERROR: test.monkey:2:5: TypeError: type mismatch: INTEGER + BOOLEAN
+  in inner() called from test.monkey:6:3
+  in outer() called from test.monkey:8:1`
+
+	if errObj.Traceback() != expected {
+		t.Errorf("wrong traceback. expected=%q, got=%q", expected, errObj.Traceback())
+	}
+
+	evaluated = testEval("try { fn() { 1 + true }() } catch (e) { e }")
+	if isError(evaluated) {
+		t.Fatalf("caught error escaped try. got=%s", evaluated.Inspect())
+	}
+
+	evaluated = testEval("fn() { len() }()")
+
+	errObj, ok = evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	if len(errObj.Trace) != 2 || errObj.Trace[0].Function != "len" || errObj.Trace[1].Function != "(anonymous)" {
+		t.Errorf("wrong trace. got=%+v", errObj.Trace)
+	}
+
+	evaluated = testEval(`"a".split(1)`)
+
+	errObj, ok = evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	if len(errObj.Trace) != 1 || errObj.Trace[0].Function != "STRING.split" {
+		t.Errorf("wrong trace. got=%+v", errObj.Trace)
+	}
+
+	// functions called by builtins get a frame at the position of the builtin call
+	program = parser.New(lexer.New("double = fn(x) {\n  x * true\n};\narray_map([1], double)")).ParseProgram()
+	evaluated = Eval(program, env)
+
+	errObj, ok = evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	expected = `ERROR: test.monkey:2:5: TypeError: type mismatch: INTEGER * BOOLEAN
+  in (anonymous)() called from test.monkey:4:1
+  in array_map() called from test.monkey:4:1`
+
+	if errObj.Traceback() != expected {
+		t.Errorf("wrong traceback. expected=%q, got=%q", expected, errObj.Traceback())
+	}
+
+	evaluated = testEval("struct V { __add: fn(a, b) { a * b } }; V() + V()")
+
+	errObj, ok = evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	if len(errObj.Trace) != 1 || errObj.Trace[0].Function != "__add" || errObj.Trace[0].Position.Line != 1 {
+		t.Errorf("wrong trace. got=%+v", errObj.Trace)
+	}
+
+	lib := filepath.Join(t.TempDir(), "lib.monkey")
+	if err := os.WriteFile(lib, []byte("Fail = fn() {\n  -true\n};"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	evaluated = testEval(fmt.Sprintf("lib = require(%q);\nlib.Fail()", lib))
+
+	errObj, ok = evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	if errObj.File != lib || errObj.Position.Line != 2 {
+		t.Errorf("wrong error location. got=%s:%s", errObj.File, errObj.Position)
+	}
+
+	if len(errObj.Trace) != 1 || errObj.Trace[0].Function != "lib.Fail" || errObj.Trace[0].Position.Line != 2 {
+		t.Errorf("wrong trace. got=%+v", errObj.Trace)
+	}
+}
+
 func TestFunctionObject(t *testing.T) {
 	input := "fn(x) { x + 2; };"
 	evaluated := testEval(input)
@@ -260,6 +1189,107 @@ func TestFunctionApplication(t *testing.T) {
 	}
 }
 
+func TestFunctionParameters(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"add = fn(a, b = 10) { a + b }; add(1)", 11},
+		{"add = fn(a, b = 10) { a + b }; add(1, 2)", 3},
+		{"f = fn(a, b = a * 2) { b }; f(4)", 8},
+		{"x = 1; f = fn(a = x) { a }; x = 5; f()", 5},
+		{"f = fn(a, ...rest) { len(rest) }; f(1)", 0},
+		{"f = fn(a, ...rest) { rest[1] }; f(1, 2, 3)", 3},
+		{"f = fn(a, b = 2, ...rest) { a + b + len(rest) }; f(1)", 3},
+		{"f = fn(...all) { len(all) }; f(1, 2, 3, 4)", 4},
+		{"f = fn(a, b = undefined) { a }; f(1, 2)", 1},
+		{"f = fn(a, b = undefined) { a }; f(1)", errorValue("identifier not found: undefined")},
+		{"f = fn(a) { a }; f(1, 2)", errorValue("f() takes a maximum 1 arguments (2 given)")},
+		{"f = fn(a, b = 1) { a }; f()", errorValue("f() takes a minimum 1 arguments (0 given)")},
+		{"f = fn() { 1 }; f(1)", errorValue("f() takes a maximum 0 arguments (1 given)")},
+		{"f = fn() { len(arguments) }; f(1, 2, 3)", 3},
+		{"f = fn(a) { arguments[1] }; f(1, 2)", 2},
+		{"f = fn() { fn() { arguments } }; f(1)", errorValue("f() takes a maximum 0 arguments (1 given)")},
+		{"array_map([5], fn() { len(arguments) })[0]", 2},
+		{"array_map([1, 2], fn(x) { x * 2 })[1]", 4},
+		{"array_map([1, 2], fn(x, i) { i })[1]", 1},
+		{"len(array_filter([1, 2, 3, 4], fn(x) { x % 2 == 0 }))", 2},
+		{"array_filter([1, 2, 3], fn(x, i) { i > 1 })[0]", 3},
+		{"array_filter([1], 1)", errorValue("second argument to `array_filter` must be callable, got INTEGER")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestDestructuring(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"[a, b] = [1, 2]; a + b", 3},
+		{"[first, second, ...rest] = [1, 2, 3, 4]; len(rest) * 10 + rest[1]", 24},
+		{"[a, ...rest] = [1]; len(rest)", 0},
+		{"[a, b] = [1]; b", nil},
+		{"[a, [b, c]] = [1, [2, 3]]; a + b + c", 6},
+		{"a = 1; b = 2; [a, b] = [b, a]; a * 10 + b", 21},
+		{"{name, age} = {\"name\": \"Ada\", \"age\": 36}; age", 36},
+		{"{name} = {\"name\": \"Ada\"}; name", "Ada"},
+		{"{\"name\": n, \"roles\": [role]} = {\"name\": \"Ada\", \"roles\": [\"admin\"]}; role", "admin"},
+		{"{missing} = {}; missing", nil},
+		{"xs = [0, 0]; [xs[0], xs[1]] = [5, 6]; xs[0] + xs[1]", 11},
+		{"f = fn([a, b], {c}) { a + b + c }; f([1, 2], {\"c\": 3})", 6},
+		{"f = fn({x, y} = {\"x\": 1, \"y\": 2}) { x + y }; f()", 3},
+		{"[a, b] = 5", errorValue("cannot destructure INTEGER as an array")},
+		{"{a} = [1]", errorValue("cannot destructure ARRAY as a hash")},
+		{"f = fn([a]) { a }; f({})", errorValue("cannot destructure HASH as an array")},
+		{"const a = 1; [a] = [2]", errorValue("cannot reassign constant a")},
+		{"[a, b] = [1, 2, 3]", errorValue("too many elements to destructure (expected 2, got 3)")},
+		{"f = fn([a]) { a }; f([1, 2])", errorValue("too many elements to destructure (expected 1, got 2)")},
+		{"[] = [1]", errorValue("too many elements to destructure (expected 0, got 1)")},
+		{"a = 0; [a, {b}] = [1, 2]", errorValue("cannot destructure INTEGER as a hash")},
+		{"a = 0; try { [a, {b}] = [1, 2] } catch (e) {}; a", 0},
+		{"a = 0; try { [a, [b]] = [1, [2, 3]] } catch (e) {}; a", 0},
+		{"const c = 1; a = 0; try { [a, c] = [1, 2] } catch (e) {}; a", 0},
+		{"xs = [0]; try { [xs[0], {b}] = [1, 2] } catch (e) {}; xs[0]", 0},
+		{"xs = [1]; ...xs", errorValue("unexpected ...xs outside of an array, hash or argument list")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestSpread(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"a = [1, 2]; b = [3]; xs = [...a, ...b, 4]; len(xs) * 10 + xs[3]", 44},
+		{"xs = [...[]]; len(xs)", 0},
+		{`d = {"a": 1, "b": 2}; o = {"b": 3}; h = {...d, ...o}; h["a"] * 10 + h["b"]`, 13},
+		{`o = {"b": 3}; h = {...o, "b": 4}; h["b"]`, 4},
+		{`o = {"b": 3}; h = {"b": 4, ...o}; h["b"]`, 3},
+		{"add = fn(a, b, c) { a + b + c }; args = [1, 2]; add(...args, 3)", 6},
+		{"count = fn(...xs) { len(xs) }; forward = fn(a, b) { count(...arguments) }; forward(1, 2)", 2},
+		{"count = fn(...xs) { len(xs) }; forward = fn(...args) { count(0, ...args) }; forward(1, 2, 3)", 4},
+		{"xs = [1]; ys = [...xs]; ys[0] = 2; xs[0]", 1},
+		{"[...1]", errorValue("cannot spread INTEGER into an array or arguments")},
+		{"{...[1]}", errorValue("cannot spread ARRAY into a hash")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
 func TestClosures(t *testing.T) {
 	input := `
 newAdder = fn(x) {
@@ -285,6 +1315,53 @@ func TestStringLiteral(t *testing.T) {
 	}
 }
 
+func TestTemplateLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"`hello`", "hello"},
+		{"name = \"Ada\"; `Hello ${name}!`", "Hello Ada!"},
+		{"`${1 + 2} and ${2.5} and ${true} and ${null}`", "3 and 2.5 and true and null"},
+		{"xs = [1, 2]; `${xs} has ${len(xs)} items`", "[1, 2] has 2 items"},
+		{"x = 1; `outer ${ `inner ${x + 1}` }`", "outer inner 2"},
+		{"f = fn(n) { `n=${n}` }; f(3)", "n=3"},
+		{"`a\\${b}\\``", "a${b}`"},
+		{"`${missing}`", errorValue("identifier not found: missing")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestUnicodeStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{`"héllo"[1]`, "é"},
+		{`"héllo"[4]`, "o"},
+		{`"🐒!"[0]`, "🐒"},
+		{`"héllo"[5]`, ""},
+		{`len("héllo")`, 5},
+		{`len("🐒")`, 1},
+		{`len("\u{1F412}\x41")`, 2},
+		{`"\u{48}\x69"`, "Hi"},
+		{`größe = 3; größe * 2`, 6},
+		{`s = ""; for (c in "añb") { s = c + s }; s`, "bña"},
+		{`n = 0; for (i, c in "🐒🐒") { n += i }; n`, 1},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
 func TestStringConcatenation(t *testing.T) {
 	input := `"Hello" + " " + "World!"`
 	evaluated := testEval(input)
@@ -307,30 +1384,30 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`len("")`, 0},
 		{`len("four")`, 4},
 		{`len("hello world")`, 11},
-		{`len(1)`, "argument to `len` not supported, got INTEGER"},
-		{`len("one", "two")`, "ArgumentError: len() takes exactly 1 argument (2 given)"},
+		{`len(1)`, errorValue("argument to `len` not supported, got INTEGER")},
+		{`len("one", "two")`, errorValue("len() takes exactly 1 argument (2 given)")},
+		{`str(255, 16)`, "ff"},
+		{`str(0b1010, 2)`, "1010"},
+		{`str(-0o755, 8)`, "-755"},
+		{`str(1_000_000)`, "1000000"},
+		{`str([1, "a"])`, "[1, a]"},
+		{`str(255, 37)`, errorValue("str() base must be between 2 and 36, got 37")},
+		{`str(2.5, 2)`, errorValue("str() expected argument #1 to be `INTEGER` got `FLOAT`")},
+		{`len({"a": 1})`, 1},
+		{`len(string_split("a b c", " "))`, 3},
+		{`string_split("", ",")[0]`, ""},
+		{`string_split("a", 1)`, errorValue("string_split() expected argument #2 to be `STRING` got `INTEGER`")},
+		{`string_trim(" a\n")`, "a"},
+		{`array_join([1, 2], ", ")`, "1, 2"},
+		{`array_join([], ",")`, ""},
+		{`len(hash_keys({}))`, 0},
+		{`hash_values([])`, errorValue("hash_values() expected argument #1 to be `HASH` got `ARRAY`")},
 	}
 
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
 
-		switch expected := tt.expected.(type) {
-		case int:
-			testIntegerObject(t, evaluated, int64(expected))
-		case string:
-			errObj, ok := evaluated.(*object.Error)
-
-			if !ok {
-				t.Errorf("object is not Error. got=%T (%+v)",
-					evaluated, evaluated)
-				continue
-			}
-
-			if errObj.Message != expected {
-				t.Errorf("wrong error message. expected=%q, got=%q",
-					expected, errObj.Message)
-			}
-		}
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
 	}
 }
 
@@ -396,8 +1473,24 @@ func TestArrayIndexExpressions(t *testing.T) {
 		},
 		{
 			"[1, 2, 3][-1]",
+			3,
+		},
+		{
+			"[1, 2, 3][-3]",
+			1,
+		},
+		{
+			"[1, 2, 3][-4]",
 			nil,
 		},
+		{
+			"xs = [1, 2, 3]; xs[-1] = 5; xs[2]",
+			5,
+		},
+		{
+			"xs = [1, 2, 3]; xs[-2] += 5; xs[1]",
+			7,
+		},
 	}
 
 	for _, tt := range tests {
@@ -412,6 +1505,79 @@ func TestArrayIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestArrayCallbacks(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"array_reduce([1, 2, 3], fn(acc, x) { acc + x }, 0)", 6},
+		{"array_reduce([], fn(acc, x) { acc + x }, 5)", 5},
+		{"array_reduce([1, 2], fn(acc, x, i) { acc + i }, 0)", 1},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	// an error in a callback stops the builtin and is returned
+	errors := []string{
+		"array_map([1, 2], fn(x) { x + true })",
+		"n = 0; array_each([1, 2], fn(x) { n += 1; x + true }); n",
+		"array_reduce([1, 2], fn(acc, x) { acc + true }, 0)",
+	}
+
+	for _, input := range errors {
+		errObj, ok := testEval(input).(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q", input)
+			continue
+		}
+
+		if errObj.Message != "type mismatch: INTEGER + BOOLEAN" {
+			t.Errorf("wrong error message for %q. got=%q", input, errObj.Message)
+		}
+	}
+}
+
+func TestSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"[1, 2, 3, 4][1:3]", inspectValue("[2, 3]")},
+		{"[1, 2, 3, 4][:2]", inspectValue("[1, 2]")},
+		{"[1, 2, 3, 4][2:]", inspectValue("[3, 4]")},
+		{"[1, 2, 3, 4][:]", inspectValue("[1, 2, 3, 4]")},
+		{"[1, 2, 3, 4][-2:]", inspectValue("[3, 4]")},
+		{"[1, 2, 3, 4][:-1]", inspectValue("[1, 2, 3]")},
+		{"[1, 2, 3, 4][::2]", inspectValue("[1, 3]")},
+		{"[1, 2, 3, 4][::-1]", inspectValue("[4, 3, 2, 1]")},
+		{"[1, 2, 3, 4][2:0:-1]", inspectValue("[3, 2]")},
+		{"[1, 2, 3, 4][10:]", inspectValue("[]")},
+		{"[1, 2, 3, 4][-10:2]", inspectValue("[1, 2]")},
+		{"[1, 2, 3, 4][null:2]", inspectValue("[1, 2]")},
+		{"xs = [1, 2]; ys = xs[:]; ys[0] = 5; xs[0]", inspectValue("1")},
+		{`"hello"[1:3]`, inspectValue("el")},
+		{`"hello"[-3:]`, inspectValue("llo")},
+		{`"héllo wörld"[::-1]`, inspectValue("dlröw olléh")},
+		{`"hello"[-1]`, inspectValue("o")},
+		{"a = [1, 2, 3]; s = a[1::9223372036854775807]; len(s) * 10 + s[0]", 12},
+		{"a = [1, 2, 3]; s = a[::-9223372036854775807 - 1]; len(s) * 10 + s[0]", 13},
+		{"a = [1, 2, 3]; len(a[3::9223372036854775807])", 0},
+		{`"abc"[::9223372036854775807]`, "a"},
+		{`"abc"[::-9223372036854775807 - 1]`, "c"},
+		{"[1, 2][::0]", errorValue("slice step cannot be zero")},
+		{`[1, 2]["a":]`, errorValue("slice indices must be integers, got STRING")},
+		{"5[1:]", errorValue("slice operator not supported: INTEGER")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
 func TestHashLiterals(t *testing.T) {
 	input := `two = "two";
 {
@@ -516,6 +1682,11 @@ func testEval(input string) object.Object {
 	program := p.ParseProgram()
 	env := object.NewEnvironment()
 
+	// inputs that don't parse can't pass for their expected value
+	if parserErrors := p.Errors(); len(parserErrors) > 0 {
+		return &object.Error{Message: "parser errors: " + strings.Join(parserErrors, "; ")}
+	}
+
 	return Eval(program, env)
 }
 
@@ -537,6 +1708,24 @@ func testBooleanObject(t *testing.T, obj object.Object, expected bool) bool {
 	return true
 }
 
+func testStringObject(t *testing.T, obj object.Object, expected string) bool {
+	result, ok := obj.(*object.String)
+
+	if !ok {
+		t.Errorf("object is not String. got=%T (%+v)", obj, obj)
+		return false
+	}
+
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%q, want=%q",
+			result.Value, expected)
+
+		return false
+	}
+
+	return true
+}
+
 func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
 	result, ok := obj.(*object.Integer)
 
@@ -555,6 +1744,51 @@ func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
 	return true
 }
 
+// testExpectedObject checks the result of evaluating input against the expected
+// value of a table test: an int, float64, bool or string for values of those
+// types, an errorValue for errors, an inspectValue for the output of Inspect()
+// and nil for NULL
+func testExpectedObject(t *testing.T, input string, evaluated object.Object, expected any) bool {
+	t.Helper()
+
+	switch expected := expected.(type) {
+	case int:
+		return testIntegerObject(t, evaluated, int64(expected))
+	case float64:
+		result, ok := evaluated.(*object.Float)
+		if !ok || result.Value != expected {
+			t.Errorf("object is not Float %g for %q. got=%T (%+v)", expected, input, evaluated, evaluated)
+			return false
+		}
+	case bool:
+		return testBooleanObject(t, evaluated, expected)
+	case string:
+		return testStringObject(t, evaluated, expected)
+	case inspectValue:
+		if evaluated == nil || evaluated.Inspect() != string(expected) {
+			t.Errorf("wrong result for %q. expected=%s, got=%+v", input, expected, evaluated)
+			return false
+		}
+	case errorValue:
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, evaluated, evaluated)
+			return false
+		}
+
+		if errObj.Message != string(expected) {
+			t.Errorf("wrong error message for %q. expected=%q, got=%q", input, expected, errObj.Message)
+			return false
+		}
+	case nil:
+		return testNullObject(t, evaluated)
+	default:
+		t.Fatalf("unsupported expected value %T for %q", expected, input)
+	}
+
+	return true
+}
+
 func testHashKey(t *testing.T, obj object.Hashable) object.HashKey {
 	t.Helper()
 
@@ -0,0 +1,40 @@
+package evaluator
+
+import (
+	"monkey/object"
+	"monkey/typing"
+)
+
+func init() {
+	builtins["hash_keys"] = &object.Builtin{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if err := typing.Check("hash_keys", args, typing.ExactArgs(1), typing.WithTypes(object.HASH_OBJ)); err != nil {
+				return newErrorFrom(err)
+			}
+
+			pairs := args[0].(*object.Hash).Pairs
+			keys := make([]object.Object, 0, len(pairs))
+			for _, pair := range pairs {
+				keys = append(keys, pair.Key)
+			}
+
+			return &object.Array{Elements: keys}
+		},
+	}
+
+	builtins["hash_values"] = &object.Builtin{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if err := typing.Check("hash_values", args, typing.ExactArgs(1), typing.WithTypes(object.HASH_OBJ)); err != nil {
+				return newErrorFrom(err)
+			}
+
+			pairs := args[0].(*object.Hash).Pairs
+			values := make([]object.Object, 0, len(pairs))
+			for _, pair := range pairs {
+				values = append(values, pair.Value)
+			}
+
+			return &object.Array{Elements: values}
+		},
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/big"
 	"monkey/object"
 	"monkey/typing"
 	"net/url"
@@ -49,7 +50,7 @@ func init() {
 				args,
 				typing.MinimumArgs(1),
 			); err != nil {
-				return newError("%s", err.Error())
+				return newErrorFrom(err)
 			}
 
 			stdout, ok := env.Get("STDOUT")
@@ -89,7 +90,7 @@ func init() {
 				typing.RangeOfArgs(0, 1),
 				typing.WithTypes(object.STRING_OBJ),
 			); err != nil {
-				return newError("%s", err.Error())
+				return newErrorFrom(err)
 			}
 
 			if len(args) == 1 {
@@ -128,32 +129,19 @@ func init() {
 				return newError("input: STDIN is not readable")
 			}
 
-			var line strings.Builder
-			var buffer [1]byte
-			for {
-				n, err := reader.Read(buffer[:])
-				if n > 0 {
-					if buffer[0] == '\n' {
-						break
-					}
-					line.WriteByte(buffer[0])
-				}
-				if err != nil {
-					if err != io.EOF {
-						return newError("input: %s", err)
-					}
-					break
-				}
+			line, _, err := readLine(reader)
+			if err != nil {
+				return newError("input: %s", err)
 			}
 
-			return &object.String{Value: strings.TrimSuffix(line.String(), "\r")}
+			return &object.String{Value: line}
 		},
 	}
 
 	builtins["kind"] = &object.Builtin{
 		Fn: func(env *object.Environment, args ...object.Object) object.Object {
 			if err := typing.Check("kind", args, typing.ExactArgs(1), typing.WithTypes(object.RESOURCE_OBJ)); err != nil {
-				return newError("%s", err.Error())
+				return newErrorFrom(err)
 			}
 
 			return &object.String{Value: args[0].(*object.Resource).Kind}
@@ -163,7 +151,7 @@ func init() {
 	builtins["open"] = &object.Builtin{
 		Fn: func(env *object.Environment, args ...object.Object) object.Object {
 			if err := typing.Check("open", args, typing.RangeOfArgs(1, 2), typing.AllOfType(object.STRING_OBJ)); err != nil {
-				return newError("%s", err.Error())
+				return newErrorFrom(err)
 			}
 
 			path := args[0].(*object.String).Value
@@ -174,7 +162,7 @@ func init() {
 
 			u, err := url.Parse(path)
 			if err != nil {
-				return newError("%s", err.Error())
+				return newErrorFrom(err)
 			}
 
 			if u.Scheme == "" {
@@ -228,7 +216,7 @@ func init() {
 	builtins["write"] = &object.Builtin{
 		Fn: func(env *object.Environment, args ...object.Object) object.Object {
 			if err := typing.Check("write", args, typing.RangeOfArgs(2, 3), typing.WithTypes(object.RESOURCE_OBJ, object.STRING_OBJ, object.INTEGER_OBJ)); err != nil {
-				return newError("%s", err.Error())
+				return newErrorFrom(err)
 			}
 
 			resource := args[0].(*object.Resource)
@@ -241,7 +229,7 @@ func init() {
 			if len(args) == 3 {
 				length := args[2].(*object.Integer).Value
 				if length < 0 {
-					return newError("ValueError: write() length must not be negative")
+					return newKindError(typing.ValueError, "write() length must not be negative")
 				}
 				if length < int64(len(data)) {
 					data = data[:length]
@@ -259,15 +247,15 @@ func init() {
 	builtins["read"] = &object.Builtin{
 		Fn: func(env *object.Environment, args ...object.Object) object.Object {
 			if err := typing.Check("read", args, typing.ExactArgs(2), typing.WithTypes(object.RESOURCE_OBJ, object.INTEGER_OBJ)); err != nil {
-				return newError("%s", err.Error())
+				return newErrorFrom(err)
 			}
 
 			length := args[1].(*object.Integer).Value
 			if length < 0 {
-				return newError("ValueError: read() length must not be negative")
+				return newKindError(typing.ValueError, "read() length must not be negative")
 			}
 			if length > int64(^uint(0)>>1) {
-				return newError("ValueError: read() length is too large")
+				return newKindError(typing.ValueError, "read() length is too large")
 			}
 
 			resource := args[0].(*object.Resource)
@@ -288,7 +276,7 @@ func init() {
 	builtins["seek"] = &object.Builtin{
 		Fn: func(env *object.Environment, args ...object.Object) object.Object {
 			if err := typing.Check("seek", args, typing.RangeOfArgs(2, 3), typing.WithTypes(object.RESOURCE_OBJ, object.INTEGER_OBJ, object.INTEGER_OBJ)); err != nil {
-				return newError("%s", err.Error())
+				return newErrorFrom(err)
 			}
 
 			resource := args[0].(*object.Resource)
@@ -312,8 +300,17 @@ func init() {
 
 	builtins["close"] = &object.Builtin{
 		Fn: func(env *object.Environment, args ...object.Object) object.Object {
-			if err := typing.Check("close", args, typing.ExactArgs(1), typing.WithTypes(object.RESOURCE_OBJ)); err != nil {
-				return newError("%s", err.Error())
+			if err := typing.Check(
+				"close",
+				args,
+				typing.ExactArgs(1),
+				typing.ArgumentOfTypes(1, object.RESOURCE_OBJ, object.CHANNEL_OBJ),
+			); err != nil {
+				return newErrorFrom(err)
+			}
+
+			if channel, ok := args[0].(*object.Channel); ok {
+				return closeChannel(channel)
 			}
 
 			resource := args[0].(*object.Resource)
@@ -331,7 +328,7 @@ func init() {
 	builtins["json_encode"] = &object.Builtin{
 		Fn: func(env *object.Environment, args ...object.Object) object.Object {
 			if err := typing.Check("json_encode", args, typing.ExactArgs(1)); err != nil {
-				return newError("%s", err.Error())
+				return newErrorFrom(err)
 			}
 
 			value, err := objectToJson(args[0])
@@ -354,7 +351,7 @@ func init() {
 				typing.ExactArgs(1),
 				typing.WithTypes(object.STRING_OBJ),
 			); err != nil {
-				return newError("%s", err.Error())
+				return newErrorFrom(err)
 			}
 
 			decoder := json.NewDecoder(strings.NewReader(args[0].(*object.String).Value))
@@ -381,6 +378,38 @@ func init() {
 	}
 }
 
+// readLine reads up to the next newline, which is dropped along with a
+// preceding carriage return. It reads a byte at a time so that nothing past
+// the line is consumed from the underlying resource. ok is false once the
+// reader is exhausted without yielding a line.
+func readLine(reader io.Reader) (string, bool, error) {
+	var line strings.Builder
+	var buffer [1]byte
+	read := false
+	for {
+		n, err := reader.Read(buffer[:])
+		if n > 0 {
+			read = true
+			if buffer[0] == '\n' {
+				break
+			}
+			line.WriteByte(buffer[0])
+		}
+		if err != nil {
+			if err != io.EOF {
+				return "", false, err
+			}
+			break
+		}
+	}
+
+	return strings.TrimSuffix(line.String(), "\r"), read, nil
+}
+
+// maxEncodedRange is the length of the longest range json_encode encodes,
+// longer ones are most likely mistakes that would exhaust memory
+const maxEncodedRange = 1 << 24
+
 func objectToJson(value object.Object) (any, error) {
 	switch value := value.(type) {
 	case *object.Null:
@@ -389,6 +418,8 @@ func objectToJson(value object.Object) (any, error) {
 		return value.Value, nil
 	case *object.Integer:
 		return value.Value, nil
+	case *object.BigInt:
+		return json.Number(value.Value.String()), nil
 	case *object.Float:
 		return value.Value, nil
 	case *object.String:
@@ -403,6 +434,15 @@ func objectToJson(value object.Object) (any, error) {
 			result[i] = converted
 		}
 		return result, nil
+	case *object.Range:
+		if value.Len() > maxEncodedRange {
+			return nil, fmt.Errorf("range of %d integers is too long to encode, the maximum is %d", value.Len(), maxEncodedRange)
+		}
+		result := make([]any, value.Len())
+		for i := range result {
+			result[i] = value.At(int64(i))
+		}
+		return result, nil
 	case *object.Hash:
 		result := make(map[string]any, len(value.Pairs))
 		for _, pair := range value.Pairs {
@@ -413,6 +453,16 @@ func objectToJson(value object.Object) (any, error) {
 			result[pair.Key.Inspect()] = converted
 		}
 		return result, nil
+	case *object.Struct:
+		result := make(map[string]any, len(value.Fields))
+		for name, field := range value.Fields {
+			converted, err := objectToJson(field)
+			if err != nil {
+				return nil, err
+			}
+			result[name] = converted
+		}
+		return result, nil
 	default:
 		return nil, fmt.Errorf("unsupported object type %s", value.Type())
 	}
@@ -428,8 +478,8 @@ func jsonToObject(value any) (object.Object, error) {
 		return &object.String{Value: value}, nil
 	case json.Number:
 		if !strings.ContainsAny(string(value), ".eE") {
-			if integer, err := strconv.ParseInt(string(value), 10, 64); err == nil {
-				return &object.Integer{Value: integer}, nil
+			if integer, ok := new(big.Int).SetString(string(value), 10); ok {
+				return newInteger(integer), nil
 			}
 		}
 		if number, err := strconv.ParseFloat(string(value), 64); err == nil {
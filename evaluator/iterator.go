@@ -0,0 +1,288 @@
+package evaluator
+
+import (
+	"monkey/ast"
+	"monkey/object"
+	"monkey/typing"
+	"runtime"
+	"sync"
+)
+
+func init() {
+	builtins["next"] = &object.Builtin{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if err := typing.Check(
+				"next",
+				args,
+				typing.RangeOfArgs(1, 2),
+				typing.WithTypes(object.ITERATOR_OBJ),
+			); err != nil {
+				return newErrorFrom(err)
+			}
+
+			value, ok := args[0].(*object.Iterator).Next()
+			if !ok {
+				// an exhausted iterator returns the default, if any
+				if len(args) == 2 {
+					return args[1]
+				}
+
+				return NULL
+			}
+
+			return value
+		},
+	}
+}
+
+// generator runs the body of a generator function on its own goroutine. The
+// caller and the body hand control back and forth through unbuffered
+// channels, so only one of them runs at any time.
+type generator struct {
+	resume chan bool          // true resumes the body, false stops it
+	yields chan object.Object // yielded values, closed when the body returns
+
+	// mu guards done and running, as the iterator may be shared between
+	// goroutines and is closed by the garbage collector. stopped is only used
+	// by the body.
+	mu      sync.Mutex
+	done    bool
+	running bool
+	stopped bool
+}
+
+// newGenerator returns an iterator over the values yielded by the body of fn,
+// evaluated in env. The body doesn't start until the first value is needed.
+func newGenerator(fn *object.Function, env *object.Environment) *object.Iterator {
+	g := &generator{resume: make(chan bool), yields: make(chan object.Object)}
+
+	// yield is a keyword, so the binding can't clash with a variable
+	env.Set("yield", &object.Builtin{Fn: g.yield}, object.BindingOptions{})
+
+	go g.run(fn.Body, env)
+
+	iterator := &object.Iterator{Next: g.next, Close: g.close}
+	// stop the body of generators that are dropped before they finish
+	runtime.AddCleanup(iterator, func(g *generator) { g.close() }, g)
+
+	return iterator
+}
+
+func (g *generator) run(body *ast.BlockStatement, env *object.Environment) {
+	defer close(g.yields)
+
+	if !<-g.resume {
+		return
+	}
+
+	if result := Eval(body, env); isError(result) {
+		g.yields <- result
+	}
+}
+
+// yield hands a value to the caller and waits to be resumed. When the
+// generator is closed instead, it returns from the body so that any finally
+// blocks run on the way out.
+func (g *generator) yield(env *object.Environment, args ...object.Object) object.Object {
+	if g.stopped {
+		return &object.ReturnValue{Value: NULL}
+	}
+
+	g.yields <- args[0]
+
+	if !<-g.resume {
+		g.stopped = true
+
+		return &object.ReturnValue{Value: NULL}
+	}
+
+	return NULL
+}
+
+func (g *generator) next() (object.Object, bool) {
+	g.mu.Lock()
+
+	if g.done {
+		g.mu.Unlock()
+
+		return nil, false
+	}
+
+	if g.running {
+		g.mu.Unlock()
+
+		return newError("generator is already running"), true
+	}
+
+	g.running = true
+	g.mu.Unlock()
+
+	g.resume <- true
+	value, ok := <-g.yields
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.running = false
+
+	if !ok || isError(value) {
+		g.done = true
+	}
+
+	return value, ok
+}
+
+func (g *generator) close() {
+	g.mu.Lock()
+
+	if g.done || g.running {
+		g.mu.Unlock()
+
+		return
+	}
+
+	g.done = true
+	g.mu.Unlock()
+
+	g.resume <- false
+
+	// wait for the body to unwind
+	for range g.yields {
+	}
+}
+
+func evalYieldStatement(ys *ast.YieldStatement, env *object.Environment) object.Object {
+	var value object.Object = NULL
+
+	if ys.Value != nil {
+		value = Eval(ys.Value, env)
+
+		if isError(value) {
+			return value
+		}
+	}
+
+	binding, ok := env.Get("yield")
+	if !ok {
+		return newError("yield outside of a generator")
+	}
+
+	return binding.Value.(*object.Builtin).Fn(env, value)
+}
+
+// iteratorOf returns an iterator over the values of an iterator or range, a
+// range returns a new one starting over every time
+func iteratorOf(value object.Object) (*object.Iterator, bool) {
+	switch value := value.(type) {
+	case *object.Iterator:
+		return value, true
+	case *object.Range:
+		return value.Iterator(), true
+	}
+
+	return nil, false
+}
+
+// closeIterator stops an iterator that won't be consumed to the end
+func closeIterator(iterator *object.Iterator) {
+	if iterator.Close != nil {
+		iterator.Close()
+	}
+}
+
+// eachValue calls fn with the index and value of each element of an array,
+// iterator or range until fn reports that it is done, returning what fn returned then.
+// An error produced by an iterator also stops the iteration and is returned.
+// Iterators that are stopped early are closed.
+func eachValue(
+	iterable object.Object,
+	fn func(index int64, value object.Object) (object.Object, bool),
+) (object.Object, bool) {
+	switch iterable := iterable.(type) {
+	case *object.Array:
+		for i, element := range iterable.Elements {
+			if result, done := fn(int64(i), element); done {
+				return result, true
+			}
+		}
+	case *object.Range:
+		return eachValue(iterable.Iterator(), fn)
+	case *object.Iterator:
+		for i := int64(0); ; i++ {
+			value, ok := iterable.Next()
+			if !ok {
+				break
+			}
+
+			if isError(value) {
+				closeIterator(iterable)
+
+				return value, true
+			}
+
+			if result, done := fn(i, value); done {
+				closeIterator(iterable)
+
+				return result, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// mapIterator returns an iterator lazily applying fn to the index and value of
+// each value of iterator. fn may return an error to end the iteration.
+func mapIterator(
+	iterator *object.Iterator,
+	fn func(index int64, value object.Object) object.Object,
+) *object.Iterator {
+	index := int64(0)
+
+	return &object.Iterator{
+		Next: func() (object.Object, bool) {
+			value, ok := iterator.Next()
+			if !ok || isError(value) {
+				return value, ok
+			}
+
+			index++
+
+			return fn(index-1, value), true
+		},
+		Close: iterator.Close,
+	}
+}
+
+// filterIterator returns an iterator lazily skipping the values of iterator
+// for which keep returns a falsy value. keep may return an error to end the
+// iteration.
+func filterIterator(
+	iterator *object.Iterator,
+	keep func(index int64, value object.Object) object.Object,
+) *object.Iterator {
+	index := int64(0)
+
+	return &object.Iterator{
+		Next: func() (object.Object, bool) {
+			for {
+				value, ok := iterator.Next()
+				if !ok || isError(value) {
+					return value, ok
+				}
+
+				index++
+
+				result := keep(index-1, value)
+				if isError(result) {
+					return result, true
+				}
+
+				if isTruthy(result) {
+					return value, true
+				}
+			}
+		},
+		Close: iterator.Close,
+	}
+}
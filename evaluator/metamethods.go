@@ -0,0 +1,135 @@
+package evaluator
+
+import (
+	"monkey/object"
+	"sync"
+)
+
+// infixMetamethods maps operators to the metamethods overloading them. > and
+// >= use the metamethods of < and <= with swapped operands, != negates __eq.
+// Comparisons evaluate to the truthiness of what their metamethod returns.
+var infixMetamethods = map[string]string{
+	"+":  "__add",
+	"-":  "__sub",
+	"*":  "__mul",
+	"/":  "__div",
+	"//": "__floordiv",
+	"%":  "__mod",
+	"**": "__pow",
+	"==": "__eq",
+	"!=": "__eq",
+	"<":  "__lt",
+	">":  "__lt",
+	"<=": "__le",
+	">=": "__le",
+}
+
+// inspecting are the values whose __str is running. Converting such a value
+// to a string again, e.g. from its own __str, shows it as usual instead of
+// calling __str forever.
+var inspecting = struct {
+	sync.Mutex
+	values map[object.Object]bool
+}{values: map[object.Object]bool{}}
+
+func init() {
+	object.InspectHook = func(value object.Object) (string, bool) {
+		fn, ok := metamethod(value, "__str")
+		if !ok {
+			return "", false
+		}
+
+		inspecting.Lock()
+		if inspecting.values[value] {
+			inspecting.Unlock()
+
+			return "", false
+		}
+		inspecting.values[value] = true
+		inspecting.Unlock()
+
+		defer func() {
+			inspecting.Lock()
+			delete(inspecting.values, value)
+			inspecting.Unlock()
+		}()
+
+		// values whose __str fails or doesn't return a string are shown as usual
+		result, ok := applyFunction(fn, []object.Object{value}, object.NewEnvironment(), "__str").(*object.String)
+		if !ok {
+			return "", false
+		}
+
+		return result.Value, true
+	}
+}
+
+// metamethod returns the function overloading an operation on value, e.g.
+// __add. Hashes define metamethods as keys and structs as methods, either way
+// they are called with the operands of the operation.
+func metamethod(value object.Object, name string) (object.Object, bool) {
+	switch value := value.(type) {
+	case *object.Hash:
+		fn, ok := hashGet(value, name)
+
+		return fn, ok && isCallable(fn)
+	case *object.Struct:
+		method, ok := value.Definition.Methods[name]
+		if !ok {
+			return nil, false
+		}
+
+		return bindMethod(method, value), true
+	}
+
+	return nil, false
+}
+
+// evalMetamethodInfixExpression calls the metamethod overloading operator on
+// the left operand, or else on the right one. It reports false when neither
+// overloads it, so that the operator falls back to its usual behaviour.
+func evalMetamethodInfixExpression(
+	operator string,
+	left, right object.Object,
+	env *object.Environment,
+) (object.Object, bool) {
+	name, ok := infixMetamethods[operator]
+	if !ok {
+		return nil, false
+	}
+
+	// only values of the same type are compared with __eq, anything else is
+	// simply not equal
+	if name == "__eq" && left.Type() != right.Type() {
+		return nil, false
+	}
+
+	if operator == ">" || operator == ">=" {
+		left, right = right, left
+	}
+
+	fn, ok := metamethod(left, name)
+	if !ok {
+		if fn, ok = metamethod(right, name); !ok {
+			return nil, false
+		}
+	}
+
+	result := callFunction(fn, []object.Object{left, right}, env, name)
+
+	if isError(result) {
+		return result, true
+	}
+
+	switch name {
+	case "__eq", "__lt", "__le":
+		holds := isTruthy(result)
+		if operator == "!=" {
+			holds = !holds
+		}
+
+		return nativeBoolToBooleanObject(holds), true
+	}
+
+	return result, true
+}
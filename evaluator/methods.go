@@ -0,0 +1,133 @@
+package evaluator
+
+import (
+	"errors"
+	"monkey/ast"
+	"monkey/object"
+	"monkey/token"
+	"monkey/typing"
+	"strings"
+)
+
+// methods maps the methods of built-in types to the builtins implementing
+// them, which receive the value the method is called on as first argument
+var methods = map[object.Type]map[string]string{
+	object.STRING_OBJ: {
+		"len":      "len",
+		"split":    "string_split",
+		"contains": "string_contains",
+		"upper":    "string_upper",
+		"lower":    "string_lower",
+		"trim":     "string_trim",
+		"int":      "int",
+	},
+	object.ARRAY_OBJ: {
+		"len":    "len",
+		"push":   "array_push",
+		"first":  "array_first",
+		"last":   "array_last",
+		"rest":   "array_rest",
+		"copy":   "array_copy",
+		"join":   "array_join",
+		"map":    "array_map",
+		"filter": "array_filter",
+		"each":   "array_each",
+		"reduce": "array_reduce",
+	},
+	object.HASH_OBJ: {
+		"len":    "len",
+		"keys":   "hash_keys",
+		"values": "hash_values",
+	},
+	object.INTEGER_OBJ: {"str": "str"},
+	object.BIGINT_OBJ:  {"str": "str"},
+	object.FLOAT_OBJ:   {"str": "str", "int": "int"},
+	object.RESOURCE_OBJ: {
+		"read":  "read",
+		"write": "write",
+		"seek":  "seek",
+		"close": "close",
+	},
+	object.ITERATOR_OBJ: {
+		"next":   "next",
+		"map":    "array_map",
+		"filter": "array_filter",
+		"each":   "array_each",
+		"reduce": "array_reduce",
+	},
+	object.RANGE_OBJ: {
+		"len":    "len",
+		"map":    "array_map",
+		"filter": "array_filter",
+		"each":   "array_each",
+		"reduce": "array_reduce",
+	},
+	object.CHANNEL_OBJ: {
+		"send":  "send",
+		"recv":  "recv",
+		"close": "close",
+	},
+}
+
+// isSelector reports whether an index expression was written as a.b or a?.b
+func isSelector(node *ast.IndexExpression) bool {
+	return node.Token.Type == token.DOT || node.Token.Type == token.OPTIONAL_CHAIN
+}
+
+// evalSelectorExpression evaluates value.name to a field or a bound method.
+// The keys of a hash take precedence over its methods, so that hashes used as
+// modules or records keep working.
+func evalSelectorExpression(value object.Object, name *object.String, env *object.Environment) object.Object {
+	if hash, ok := value.(*object.Hash); ok {
+		if field, ok := hashGet(hash, name.Value); ok {
+			return field
+		}
+	}
+
+	table, ok := methods[value.Type()]
+	if method, found := table[name.Value]; found {
+		return bindBuiltinMethod(method, value, name.Value)
+	}
+
+	// values without methods report the errors of the index operator, and
+	// missing hash keys are NULL
+	if !ok || value.Type() == object.HASH_OBJ {
+		return evalIndexExpression(value, name, env)
+	}
+
+	return newKindError(typing.TypeError, "%s has no method %s", value.Type(), name.Value)
+}
+
+// bindBuiltinMethod returns a builtin calling the builtin named builtin with
+// receiver prepended to its arguments. Its errors are reported for the method,
+// e.g. STRING.split, without counting the receiver.
+func bindBuiltinMethod(builtin string, receiver object.Object, name string) *object.Builtin {
+	method := builtins[builtin]
+	name = string(receiver.Type()) + "." + name
+
+	return &object.Builtin{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			result := method.Fn(env, append([]object.Object{receiver}, args...)...)
+
+			// errors of the functions the method calls back have a traceback
+			err, ok := result.(*object.Error)
+			if !ok || len(err.Trace) != 0 {
+				return result
+			}
+
+			var typingErr *typing.Error
+
+			if errors.As(err.Cause, &typingErr) {
+				err.Message = typingErr.Method(name).Message
+			} else if rest, found := strings.CutPrefix(err.Message, builtin); found {
+				// other errors start with the name of the builtin, e.g. str() or close:
+				if strings.HasPrefix(rest, "()") || strings.HasPrefix(rest, ":") {
+					err.Message = name + rest
+				}
+			}
+
+			return result
+		},
+		Name: name,
+	}
+}
@@ -1,17 +1,23 @@
 package evaluator
 
 import (
+	"math"
+	"math/big"
 	"monkey/object"
 	"monkey/typing"
 	"os"
 	"path/filepath"
+	"strings"
+	"unicode/utf8"
 )
 
 var (
-	NULL    = &object.Null{}
-	TRUE    = &object.Boolean{Value: true}
-	FALSE   = &object.Boolean{Value: false}
-	VERSION = &object.String{Value: "v0.2.7"}
+	NULL     = &object.Null{}
+	TRUE     = &object.Boolean{Value: true}
+	FALSE    = &object.Boolean{Value: false}
+	BREAK    = &object.Break{}
+	CONTINUE = &object.Continue{}
+	VERSION  = &object.String{Value: "v0.2.7"}
 )
 
 func init() {
@@ -25,7 +31,7 @@ func init() {
 				typing.ExactArgs(1),
 				typing.WithTypes(object.STRING_OBJ),
 			); err != nil {
-				return newError("%s", err.Error())
+				return newErrorFrom(err)
 			}
 
 			file := args[0].Inspect()
@@ -43,18 +49,35 @@ func init() {
 
 			evaluated := Run(string(data), abs, filepath.Dir(abs), false, moduleEnv)
 
-			if evaluated != nil && evaluated.Type() == object.ERROR_OBJ {
-				return newError(
-					"error in required file (%s):\n %s",
-					file,
-					evaluated.Inspect(),
-				)
+			if isError(evaluated) {
+				return evaluated
 			}
 
 			return moduleEnv.ExportedHash()
 		},
 	}
 
+	builtins["error"] = &object.Builtin{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if err := typing.Check(
+				"error",
+				args,
+				typing.RangeOfArgs(1, 2),
+				typing.WithTypes(object.STRING_OBJ),
+			); err != nil {
+				return newErrorFrom(err)
+			}
+
+			var cause object.Object = NULL
+
+			if len(args) == 2 {
+				cause = args[1]
+			}
+
+			return newErrorHash(args[0].Inspect(), "Error", cause)
+		},
+	}
+
 	builtins["len"] = &object.Builtin{
 		Fn: func(env *object.Environment, args ...object.Object) object.Object {
 			if err := typing.Check(
@@ -62,16 +85,20 @@ func init() {
 				args,
 				typing.ExactArgs(1),
 			); err != nil {
-				return newError("%s", err.Error())
+				return newErrorFrom(err)
 			}
 
 			switch arg := args[0].(type) {
 			case *object.Array:
 				return &object.Integer{Value: int64(len(arg.Elements))}
 			case *object.String:
-				return &object.Integer{Value: int64(len(arg.Value))}
+				return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+			case *object.Hash:
+				return &object.Integer{Value: int64(len(arg.Pairs))}
+			case *object.Range:
+				return &object.Integer{Value: arg.Len()}
 			default:
-				return newError("argument to `len` not supported, got %s",
+				return newKindError(typing.TypeError, "argument to `len` not supported, got %s",
 					args[0].Type())
 			}
 		},
@@ -84,10 +111,85 @@ func init() {
 				args,
 				typing.ExactArgs(1),
 			); err != nil {
-				return newError("%s", err.Error())
+				return newErrorFrom(err)
 			}
 
 			return &object.String{Value: string(args[0].Type())}
 		},
 	}
+
+	builtins["str"] = &object.Builtin{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if err := typing.Check("str", args, typing.RangeOfArgs(1, 2)); err != nil {
+				return newErrorFrom(err)
+			}
+
+			if len(args) == 1 {
+				return &object.String{Value: args[0].Inspect()}
+			}
+
+			// only integers can be formatted in a base
+			check := typing.AllOfType(object.INTEGER_OBJ)
+			if args[0].Type() == object.BIGINT_OBJ {
+				check = typing.WithTypes(object.BIGINT_OBJ, object.INTEGER_OBJ)
+			}
+			if err := typing.Check("str", args, check); err != nil {
+				return newErrorFrom(err)
+			}
+
+			base := args[1].(*object.Integer).Value
+
+			if base < 2 || base > 36 {
+				return newKindError(typing.ValueError, "str() base must be between 2 and 36, got %d", base)
+			}
+
+			return &object.String{Value: bigIntValue(args[0]).Text(int(base))}
+		},
+	}
+
+	builtins["int"] = &object.Builtin{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if err := typing.Check("int", args, typing.RangeOfArgs(1, 2)); err != nil {
+				return newErrorFrom(err)
+			}
+
+			base := int64(10)
+
+			if len(args) == 2 {
+				// only strings can be parsed in a base
+				if err := typing.Check(
+					"int",
+					args,
+					typing.WithTypes(object.STRING_OBJ, object.INTEGER_OBJ),
+				); err != nil {
+					return newErrorFrom(err)
+				}
+
+				// base 0 infers the base from a 0x, 0o or 0b prefix like number literals
+				base = args[1].(*object.Integer).Value
+				if base != 0 && (base < 2 || base > 36) {
+					return newKindError(typing.ValueError, "int() base must be 0 or between 2 and 36, got %d", base)
+				}
+			}
+
+			switch arg := args[0].(type) {
+			case *object.Integer, *object.BigInt:
+				return arg
+			case *object.Float:
+				if math.IsNaN(arg.Value) || math.IsInf(arg.Value, 0) {
+					return newKindError(typing.ValueError, "int() cannot convert %s to an integer", arg.Inspect())
+				}
+				value, _ := big.NewFloat(arg.Value).Int(nil)
+				return newInteger(value)
+			case *object.String:
+				value, ok := new(big.Int).SetString(strings.TrimSpace(arg.Value), int(base))
+				if !ok {
+					return newKindError(typing.ValueError, "int() invalid literal for base %d: %q", base, arg.Value)
+				}
+				return newInteger(value)
+			default:
+				return newKindError(typing.TypeError, "int() argument must be a string or a number, got `%s`", arg.Type())
+			}
+		},
+	}
 }
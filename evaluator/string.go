@@ -0,0 +1,62 @@
+package evaluator
+
+import (
+	"monkey/object"
+	"monkey/typing"
+	"strings"
+)
+
+func init() {
+	builtins["string_split"] = &object.Builtin{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if err := typing.Check(
+				"string_split",
+				args,
+				typing.ExactArgs(2),
+				typing.WithTypes(object.STRING_OBJ, object.STRING_OBJ),
+			); err != nil {
+				return newErrorFrom(err)
+			}
+
+			parts := strings.Split(args[0].(*object.String).Value, args[1].(*object.String).Value)
+			elements := make([]object.Object, len(parts))
+			for i, part := range parts {
+				elements[i] = &object.String{Value: part}
+			}
+
+			return &object.Array{Elements: elements}
+		},
+	}
+
+	builtins["string_contains"] = &object.Builtin{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if err := typing.Check(
+				"string_contains",
+				args,
+				typing.ExactArgs(2),
+				typing.WithTypes(object.STRING_OBJ, object.STRING_OBJ),
+			); err != nil {
+				return newErrorFrom(err)
+			}
+
+			return nativeBoolToBooleanObject(strings.Contains(args[0].(*object.String).Value, args[1].(*object.String).Value))
+		},
+	}
+
+	builtins["string_upper"] = stringBuiltin("string_upper", strings.ToUpper)
+	builtins["string_lower"] = stringBuiltin("string_lower", strings.ToLower)
+	builtins["string_trim"] = stringBuiltin("string_trim", strings.TrimSpace)
+}
+
+// stringBuiltin returns a builtin taking a single string and returning fn applied to it
+func stringBuiltin(name string, fn func(string) string) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if err := typing.Check(name, args, typing.ExactArgs(1), typing.WithTypes(object.STRING_OBJ)); err != nil {
+				return newErrorFrom(err)
+			}
+
+			return &object.String{Value: fn(args[0].(*object.String).Value)}
+		},
+	}
+}
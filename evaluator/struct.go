@@ -0,0 +1,114 @@
+package evaluator
+
+import (
+	"monkey/ast"
+	"monkey/object"
+	"monkey/typing"
+)
+
+// internalTypes are the types of the errors and control flow signals passed
+// around while evaluating, instances of a struct named after one of them would
+// be taken for it
+var internalTypes = map[string]bool{
+	string(object.RETURN_VALUE_OBJ): true,
+	string(object.BREAK_OBJ):        true,
+	string(object.CONTINUE_OBJ):     true,
+	string(object.ERROR_OBJ):        true,
+}
+
+// evalStructStatement binds the name of the struct to a constructor taking
+// the values of the fields in declaration order
+func evalStructStatement(ss *ast.StructStatement, env *object.Environment) object.Object {
+	name := ss.Name.Value
+
+	if typePatterns[name] || internalTypes[name] {
+		return newError("cannot declare struct %s, %s is a builtin type", name, name)
+	}
+
+	if binding, ok := env.Lookup(name); ok {
+		if binding.SuperGlobal {
+			return newError("cannot reassign a superglobal")
+		}
+
+		if binding.Constant {
+			return newError("cannot redeclare constant %s", name)
+		}
+	}
+
+	definition := &object.StructType{Name: name, Methods: map[string]*object.Function{}}
+
+	for _, field := range ss.Fields {
+		definition.Fields = append(definition.Fields, field.Value)
+	}
+
+	for _, method := range ss.Methods {
+		definition.Methods[method.Name.Value] = Eval(method.Function, env).(*object.Function)
+	}
+
+	constructor := &object.Builtin{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if err := typing.Check(name, args, typing.ExactArgs(len(definition.Fields))); err != nil {
+				return newErrorFrom(err)
+			}
+
+			instance := &object.Struct{Definition: definition, Fields: make(map[string]object.Object, len(args))}
+
+			for i, field := range definition.Fields {
+				instance.Fields[field] = args[i]
+			}
+
+			return instance
+		},
+	}
+
+	env.Set(name, constructor, object.BindingOptions{})
+
+	return NULL
+}
+
+// evalStructIndexExpression returns the value of a field, a method bound to
+// the instance, or the result of the __index method for anything else
+func evalStructIndexExpression(instance *object.Struct, index object.Object, env *object.Environment) object.Object {
+	if name, ok := index.(*object.String); ok {
+		if value, ok := instance.Fields[name.Value]; ok {
+			return value
+		}
+
+		if method, ok := instance.Definition.Methods[name.Value]; ok {
+			return bindMethod(method, instance)
+		}
+	}
+
+	if fn, ok := metamethod(instance, "__index"); ok {
+		return callFunction(fn, []object.Object{instance, index}, env, "__index")
+	}
+
+	if name, ok := index.(*object.String); ok {
+		return newKindError(typing.TypeError, "%s has no field or method %s", instance.Type(), name.Value)
+	}
+
+	return newKindError(typing.TypeError, "cannot index %s with %s", instance.Type(), index.Type())
+}
+
+// bindMethod returns a copy of method in which self refers to instance
+func bindMethod(method *object.Function, instance *object.Struct) *object.Function {
+	env := object.NewEnclosedEnvironment(method.Env)
+	env.Set("self", instance, object.BindingOptions{Constant: true})
+
+	bound := *method
+	bound.Env = env
+
+	return &bound
+}
+
+// evalStructFieldAssignment sets a field declared by the type of instance
+func evalStructFieldAssignment(instance *object.Struct, index, value object.Object) object.Object {
+	name, ok := index.(*object.String)
+	if !ok || !instance.Definition.HasField(name.Value) {
+		return newKindError(typing.TypeError, "%s has no field %s", instance.Type(), index.Inspect())
+	}
+
+	instance.Fields[name.Value] = value
+
+	return NULL
+}
@@ -3,6 +3,9 @@ package lexer
 import (
 	"bytes"
 	"monkey/token"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Lexer is a source code lexing struct
@@ -10,12 +13,16 @@ type Lexer struct {
 	input        string
 	position     int
 	readPosition int
-	ch           byte
+	ch           rune
+	line         int
+	column       int
+	// templates holds the brace depth of every template interpolation being lexed
+	templates []int
 }
 
 // New creates a new instance of Lexer
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, line: 1}
 
 	l.readChar()
 
@@ -32,24 +39,48 @@ func (l *Lexer) NextToken() token.Token {
 
 	l.skipWhitespace()
 
+	position := token.Position{Line: l.line, Column: l.column}
+
 	switch l.ch {
 	case '#':
 		tok.Type = token.HASH
 		tok.Literal = l.readLine()
 	case '.':
-		tok = l.newToken(token.DOT)
+		if l.peekChar() == '.' && l.readPosition+1 < len(l.input) && l.input[l.readPosition+1] == '.' {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+		} else {
+			tok = l.newToken(token.DOT)
+		}
 	case '=':
 		if l.peekChar() == '=' {
 			ch := l.ch
 			l.readChar()
 			tok = token.Token{Type: token.EQ, Literal: string(ch) + string(l.ch)}
+		} else if l.peekChar() == '>' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.ARROW, Literal: string(ch) + string(l.ch)}
 		} else {
 			tok = l.newToken(token.ASSIGN)
 		}
 	case '+':
-		tok = l.newToken(token.PLUS)
+		if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.PLUS_ASSIGN, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = l.newToken(token.PLUS)
+		}
 	case '-':
-		tok = l.newToken(token.MINUS)
+		if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.MINUS_ASSIGN, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = l.newToken(token.MINUS)
+		}
 	case '!':
 		if l.peekChar() == '=' {
 			ch := l.ch
@@ -58,14 +89,86 @@ func (l *Lexer) NextToken() token.Token {
 		} else {
 			tok = l.newToken(token.BANG)
 		}
+	case '&':
+		if l.peekChar() == '&' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.AND, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = l.newToken(token.ILLEGAL)
+		}
+	case '|':
+		if l.peekChar() == '|' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.OR, Literal: string(ch) + string(l.ch)}
+		} else if l.peekChar() == '>' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.PIPE, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = l.newToken(token.ILLEGAL)
+		}
+	case '?':
+		if l.peekChar() == '?' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.NULLISH, Literal: string(ch) + string(l.ch)}
+		} else if l.peekChar() == '.' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.OPTIONAL_CHAIN, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = l.newToken(token.ILLEGAL)
+		}
 	case '/':
-		tok = l.newToken(token.SLASH)
+		if l.peekChar() == '/' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.DOUBLE_SLASH, Literal: string(ch) + string(l.ch)}
+		} else if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.SLASH_ASSIGN, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = l.newToken(token.SLASH)
+		}
 	case '*':
-		tok = l.newToken(token.ASTERISK)
+		if l.peekChar() == '*' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.DOUBLE_ASTERISK, Literal: string(ch) + string(l.ch)}
+		} else if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.ASTERISK_ASSIGN, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = l.newToken(token.ASTERISK)
+		}
+	case '%':
+		if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.PERCENT_ASSIGN, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = l.newToken(token.PERCENT)
+		}
 	case '<':
-		tok = l.newToken(token.LT)
+		if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.LTEQ, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = l.newToken(token.LT)
+		}
 	case '>':
-		tok = l.newToken(token.GT)
+		if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.GTEQ, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = l.newToken(token.GT)
+		}
 	case ';':
 		tok = l.newToken(token.SEMICOLON)
 	case ',':
@@ -75,9 +178,23 @@ func (l *Lexer) NextToken() token.Token {
 	case ')':
 		tok = l.newToken(token.RPAREN)
 	case '{':
+		if len(l.templates) > 0 {
+			l.templates[len(l.templates)-1]++
+		}
+
 		tok = l.newToken(token.LBRACE)
 	case '}':
-		tok = l.newToken(token.RBRACE)
+		if len(l.templates) > 0 && l.templates[len(l.templates)-1] == 0 {
+			// the end of an interpolation, the template text continues
+			l.templates = l.templates[:len(l.templates)-1]
+			tok = l.readTemplate()
+		} else {
+			if len(l.templates) > 0 {
+				l.templates[len(l.templates)-1]--
+			}
+
+			tok = l.newToken(token.RBRACE)
+		}
 	case '[':
 		tok = l.newToken(token.LBRACKET)
 	case ']':
@@ -87,6 +204,8 @@ func (l *Lexer) NextToken() token.Token {
 	case '"':
 		tok.Type = token.STRING
 		tok.Literal = l.readString()
+	case '`':
+		tok = l.readTemplate()
 	case ':':
 		tok = l.newToken(token.COLON)
 	case 0:
@@ -96,19 +215,14 @@ func (l *Lexer) NextToken() token.Token {
 		if l.isLetter() {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
+			tok.Position = position
 
 			return tok
 		}
 
 		if l.isDigit() {
-			tok.Literal = l.readNumber()
-			if bytes.Contains([]byte(tok.Literal), []byte(".")) ||
-				bytes.Contains([]byte(tok.Literal), []byte("e")) ||
-				bytes.Contains([]byte(tok.Literal), []byte("E")) {
-				tok.Type = token.FLOAT
-			} else {
-				tok.Type = token.INT
-			}
+			tok.Literal, tok.Type = l.readNumber()
+			tok.Position = position
 
 			return tok
 		}
@@ -118,6 +232,8 @@ func (l *Lexer) NextToken() token.Token {
 
 	l.readChar()
 
+	tok.Position = position
+
 	return tok
 }
 
@@ -148,25 +264,8 @@ func (l *Lexer) readString() string {
 	for {
 		l.readChar()
 
-		// Support some basic escapes like \"
 		if l.ch == '\\' {
-			switch l.peekChar() {
-			case '"':
-				b.WriteByte('"')
-			case '\'':
-				b.WriteByte('\'')
-			case 'n':
-				b.WriteByte('\n')
-			case 'r':
-				b.WriteByte('\r')
-			case 't':
-				b.WriteByte('\t')
-			case '\\':
-				b.WriteByte('\\')
-			}
-
-			// Skip over the '\\' and the matched single escape char
-			l.readChar()
+			l.readEscape(&b)
 
 			continue
 		}
@@ -175,23 +274,137 @@ func (l *Lexer) readString() string {
 			break
 		}
 
-		b.WriteByte(l.ch)
+		b.WriteRune(l.ch)
 	}
 
 	return b.String()
 }
 
-func (l *Lexer) readNumber() string {
+// readTemplate reads the text of a template literal following a backtick or
+// the closing brace of an interpolation. The text ends either at the closing
+// backtick, giving a TEMPLATE token, or at the ${ of the next interpolation,
+// giving a TEMPLATE_PART token.
+func (l *Lexer) readTemplate() token.Token {
+	var b bytes.Buffer
+
+	for {
+		l.readChar()
+
+		if l.ch == '\\' {
+			l.readEscape(&b)
+
+			continue
+		}
+
+		if l.ch == '$' && l.peekChar() == '{' {
+			l.readChar()
+			l.templates = append(l.templates, 0)
+
+			return token.Token{Type: token.TEMPLATE_PART, Literal: b.String()}
+		}
+
+		if l.ch == '`' || l.ch == 0 {
+			return token.Token{Type: token.TEMPLATE, Literal: b.String()}
+		}
+
+		b.WriteRune(l.ch)
+	}
+}
+
+// readEscape writes the character escaped by the backslash at the current position
+func (l *Lexer) readEscape(b *bytes.Buffer) {
+	// Skip over the '\\' to the escaped char
+	l.readChar()
+
+	switch l.ch {
+	case '"', '\'', '`', '$', '\\':
+		b.WriteRune(l.ch)
+	case 'n':
+		b.WriteByte('\n')
+	case 'r':
+		b.WriteByte('\r')
+	case 't':
+		b.WriteByte('\t')
+	case 'u':
+		// \u{1F600}
+		if l.peekChar() != '{' {
+			return
+		}
+
+		l.readChar()
+
+		if r, ok := l.readHexRune(6, '}'); ok {
+			b.WriteRune(r)
+		}
+	case 'x':
+		// \xE9, exactly two hex digits
+		if r, ok := l.readHexRune(2, 0); ok {
+			b.WriteRune(r)
+		}
+	}
+}
+
+// readHexRune reads up to maxDigits hex digits following the current
+// character, and the terminator if it isn't 0, returning the code point they
+// denote. Without a terminator exactly maxDigits digits are required.
+func (l *Lexer) readHexRune(maxDigits int, terminator rune) (rune, bool) {
+	var digits []rune
+
+	for len(digits) < maxDigits && isHexDigit(l.peekChar()) {
+		l.readChar()
+		digits = append(digits, l.ch)
+	}
+
+	if terminator != 0 {
+		if l.peekChar() != terminator {
+			return 0, false
+		}
+
+		l.readChar()
+	} else if len(digits) != maxDigits {
+		return 0, false
+	}
+
+	value, err := strconv.ParseUint(string(digits), 16, 32)
+
+	if err != nil || !utf8.ValidRune(rune(value)) {
+		return 0, false
+	}
+
+	return rune(value), true
+}
+
+func isHexDigit(ch rune) bool {
+	return '0' <= ch && ch <= '9' || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
+}
+
+// readNumber reads an integer or float literal. Integers may use the 0x, 0o
+// and 0b prefixes and any number may separate its digits with underscores,
+// the parser rejects misplaced underscores.
+func (l *Lexer) readNumber() (string, token.Type) {
 	position := l.position
 	end := position
+	tokenType := token.INT
 
-	for end < len(l.input) && l.isDigitAt(l.input[end]) {
+	if l.ch == '0' && end+1 < len(l.input) && bytes.IndexByte([]byte("xXoObB"), l.input[end+1]) >= 0 {
+		end += 2
+		for end < len(l.input) && (isHexDigit(rune(l.input[end])) || l.input[end] == '_') {
+			end++
+		}
+
+		l.skipTo(end)
+
+		return l.input[position:end], tokenType
+	}
+
+	for end < len(l.input) && (l.isDigitAt(l.input[end]) || l.input[end] == '_') {
 		end++
 	}
 
 	if end+1 < len(l.input) && l.input[end] == '.' && l.isDigitAt(l.input[end+1]) {
+		tokenType = token.FLOAT
 		end += 2
-		for end < len(l.input) && l.isDigitAt(l.input[end]) {
+		for end < len(l.input) && (l.isDigitAt(l.input[end]) || l.input[end] == '_') {
 			end++
 		}
 	}
@@ -202,6 +415,7 @@ func (l *Lexer) readNumber() string {
 			exponentEnd++
 		}
 		if exponentEnd < len(l.input) && l.isDigitAt(l.input[exponentEnd]) {
+			tokenType = token.FLOAT
 			end = exponentEnd + 1
 			for end < len(l.input) && l.isDigitAt(l.input[end]) {
 				end++
@@ -209,15 +423,17 @@ func (l *Lexer) readNumber() string {
 		}
 	}
 
-	l.position = end
-	l.readPosition = end + 1
-	if end >= len(l.input) {
-		l.ch = 0
-	} else {
-		l.ch = l.input[end]
-	}
+	l.skipTo(end)
+
+	return l.input[position:end], tokenType
+}
 
-	return l.input[position:end]
+// skipTo moves to the character at the byte offset end of the current line
+func (l *Lexer) skipTo(end int) {
+	// readChar counts the column of the last skipped character
+	l.column += end - l.position - 1
+	l.readPosition = end
+	l.readChar()
 }
 
 func (l *Lexer) isDigitAt(ch byte) bool {
@@ -234,23 +450,34 @@ func (l *Lexer) readIdentifier() string {
 	return l.input[position:l.position]
 }
 
+// readChar decodes the next UTF-8 character of the input
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
+
+	width := 1
+
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
 	} else {
-		l.ch = l.input[l.readPosition]
+		l.ch, width = utf8.DecodeRuneInString(l.input[l.readPosition:])
 	}
 
 	l.position = l.readPosition
-	l.readPosition++
+	l.readPosition += width
+	l.column++
 }
 
-func (l *Lexer) peekChar() byte {
+func (l *Lexer) peekChar() rune {
 	if l.readPosition >= len(l.input) {
 		return 0
 	}
 
-	return l.input[l.readPosition]
+	ch, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+
+	return ch
 }
 
 func (l *Lexer) isDigit() bool {
@@ -258,5 +485,5 @@ func (l *Lexer) isDigit() bool {
 }
 
 func (l *Lexer) isLetter() bool {
-	return 'a' <= l.ch && l.ch <= 'z' || 'A' <= l.ch && l.ch <= 'Z' || l.ch == '_'
+	return unicode.IsLetter(l.ch) || l.ch == '_'
 }
@@ -25,6 +25,10 @@ return false;
 
 10 == 10;
 10 != 9;
+a && b || c;
+a <= b >= c % d ** e // f;
+...rest.x;
+a?.b ?? c |> d;
 
 "foobar"
 "foo bar"
@@ -110,6 +114,37 @@ return false;
 		{token.NOTEQ, "!="},
 		{token.INT, "9"},
 		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.AND, "&&"},
+		{token.IDENT, "b"},
+		{token.OR, "||"},
+		{token.IDENT, "c"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.LTEQ, "<="},
+		{token.IDENT, "b"},
+		{token.GTEQ, ">="},
+		{token.IDENT, "c"},
+		{token.PERCENT, "%"},
+		{token.IDENT, "d"},
+		{token.DOUBLE_ASTERISK, "**"},
+		{token.IDENT, "e"},
+		{token.DOUBLE_SLASH, "//"},
+		{token.IDENT, "f"},
+		{token.SEMICOLON, ";"},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "rest"},
+		{token.DOT, "."},
+		{token.IDENT, "x"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.OPTIONAL_CHAIN, "?."},
+		{token.IDENT, "b"},
+		{token.NULLISH, "??"},
+		{token.IDENT, "c"},
+		{token.PIPE, "|>"},
+		{token.IDENT, "d"},
+		{token.SEMICOLON, ";"},
 		{token.STRING, "foobar"},
 		{token.STRING, "foo bar"},
 		{token.STRING, "foo \"bar\""},
@@ -145,3 +180,170 @@ return false;
 		}
 	}
 }
+
+func TestTemplateTokens(t *testing.T) {
+	input := "`plain` `a ${x} b ${ {\"k\": `in${y}`}[\"k\"] } c` `\\${x} \\``"
+
+	tests := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{token.TEMPLATE, "plain"},
+		{token.TEMPLATE_PART, "a "},
+		{token.IDENT, "x"},
+		{token.TEMPLATE_PART, " b "},
+		{token.LBRACE, "{"},
+		{token.STRING, "k"},
+		{token.COLON, ":"},
+		{token.TEMPLATE_PART, "in"},
+		{token.IDENT, "y"},
+		{token.TEMPLATE, ""},
+		{token.RBRACE, "}"},
+		{token.LBRACKET, "["},
+		{token.STRING, "k"},
+		{token.RBRACKET, "]"},
+		{token.TEMPLATE, " c"},
+		{token.TEMPLATE, "${x} `"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestUnicode(t *testing.T) {
+	input := `größe = "héllo 🐒"; π_2 = 3;
+"\u{e9}\u{1F412}\x41\u{110000}\xZ" 日本 `
+
+	tests := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+		column          int
+	}{
+		{token.IDENT, "größe", 1},
+		{token.ASSIGN, "=", 7},
+		{token.STRING, "héllo 🐒", 9},
+		{token.SEMICOLON, ";", 18},
+		{token.IDENT, "π_2", 20},
+		{token.ASSIGN, "=", 24},
+		{token.INT, "3", 26},
+		{token.SEMICOLON, ";", 27},
+		{token.STRING, "é🐒AZ", 1},
+		{token.IDENT, "日本", 36},
+		{token.EOF, "", 39},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if tok.Column != tt.column {
+			t.Fatalf("tests[%d] - column wrong. expected=%d, got=%d",
+				i, tt.column, tok.Column)
+		}
+	}
+}
+
+func TestNumberLiterals(t *testing.T) {
+	input := "0xFF 0o755 0b1010 1_000_000 1_000.5 1e3 0XaBe 2.5E-3 0x 7"
+
+	tests := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{token.INT, "0xFF"},
+		{token.INT, "0o755"},
+		{token.INT, "0b1010"},
+		{token.INT, "1_000_000"},
+		{token.FLOAT, "1_000.5"},
+		{token.FLOAT, "1e3"},
+		{token.INT, "0XaBe"},
+		{token.FLOAT, "2.5E-3"},
+		{token.INT, "0x"},
+		{token.INT, "7"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestTokenPositions(t *testing.T) {
+	input := `x = 10;
+  if (x >= 1.5) {
+	"a b" }`
+
+	tests := []struct {
+		expectedType token.Type
+		line         int
+		column       int
+	}{
+		{token.IDENT, 1, 1},
+		{token.ASSIGN, 1, 3},
+		{token.INT, 1, 5},
+		{token.SEMICOLON, 1, 7},
+		{token.IF, 2, 3},
+		{token.LPAREN, 2, 6},
+		{token.IDENT, 2, 7},
+		{token.GTEQ, 2, 9},
+		{token.FLOAT, 2, 12},
+		{token.RPAREN, 2, 15},
+		{token.LBRACE, 2, 17},
+		{token.STRING, 3, 2},
+		{token.RBRACE, 3, 8},
+		{token.EOF, 3, 9},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Line != tt.line || tok.Column != tt.column {
+			t.Fatalf("tests[%d] - position wrong. expected=%d:%d, got=%s",
+				i, tt.line, tt.column, tok.Position)
+		}
+	}
+}
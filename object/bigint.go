@@ -0,0 +1,41 @@
+package object
+
+import (
+	"hash/fnv"
+	"math/big"
+)
+
+// BigInt is an integer too large to be represented by an Integer. Integer
+// arithmetic promotes to a BigInt on overflow and results that fit in 64
+// bits are demoted back to an Integer, so the two never hold equal values.
+type BigInt struct {
+	Value *big.Int
+}
+
+func (b *BigInt) Type() Type {
+	return BIGINT_OBJ
+}
+
+func (b *BigInt) Inspect() string {
+	return b.Value.String()
+}
+
+func (b *BigInt) Clone() Object {
+	return &BigInt{Value: new(big.Int).Set(b.Value)}
+}
+
+func (b *BigInt) HashKey() (HashKey, error) {
+	h := fnv.New64a()
+	_, err := h.Write(b.Value.Bytes())
+	if err != nil {
+		return HashKey{}, err
+	}
+
+	value := h.Sum64()
+	// Keep values differing only in sign apart.
+	if b.Value.Sign() < 0 {
+		value = ^value
+	}
+
+	return HashKey{Type: b.Type(), Value: value}, nil
+}
@@ -4,6 +4,9 @@ type BuiltinFunction func(env *Environment, args ...Object) Object
 
 type Builtin struct {
 	Fn BuiltinFunction
+	// Name is shown in tracebacks when the builtin is called without one,
+	// e.g. STRING.split for the methods of values. It may be empty.
+	Name string
 }
 
 func (b *Builtin) Type() Type {
@@ -0,0 +1,14 @@
+package object
+
+// Channel passes values between functions running concurrently with spawn
+type Channel struct {
+	Value chan Object
+}
+
+func (c *Channel) Type() Type {
+	return CHANNEL_OBJ
+}
+
+func (c *Channel) Inspect() string {
+	return "<channel>"
+}
@@ -1,6 +1,9 @@
 package object
 
-import "unicode"
+import (
+	"sync"
+	"unicode"
+)
 
 // Binding is an object that holds a bound object
 type Binding struct {
@@ -11,12 +14,18 @@ type Binding struct {
 // BindingOptions is an object that holds options for a binding
 type BindingOptions struct {
 	SuperGlobal bool
+	Constant    bool
 }
 
-// Environment is an object that holds a mapping of names to bound objets
+// Environment is an object that holds a mapping of names to bound objets. It
+// is safe for concurrent use. Functions started by spawn run in a clone of the
+// environments they close over, but generators run their body on a goroutine
+// of its own.
 type Environment struct {
+	mu    sync.RWMutex
 	store map[string]Binding
 	outer *Environment
+	block bool
 }
 
 // NewEnvironment constructs a new Environment object to hold bindings
@@ -35,16 +44,29 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 	return env
 }
 
+// NewBlockEnvironment returns a new enclosed Environment for a block scope such
+// as the body of an if or a loop. Only let and const declarations are stored in
+// a block scope, assignments fall through to the enclosing scopes (see Assign).
+func NewBlockEnvironment(outer *Environment) *Environment {
+	env := NewEnclosedEnvironment(outer)
+
+	env.block = true
+
+	return env
+}
+
 // NewModuleEnvironment creates a new environment for a required module file
 func NewModuleEnvironment(parent *Environment) *Environment {
 	env := NewEnvironment()
 
 	for current := parent; current != nil; current = current.outer {
+		current.mu.RLock()
 		for name, binding := range current.store {
 			if binding.SuperGlobal {
 				env.Set(name, binding.Value, binding.BindingOptions)
 			}
 		}
+		current.mu.RUnlock()
 	}
 
 	return env
@@ -57,6 +79,9 @@ func NewModuleEnvironment(parent *Environment) *Environment {
 func (e *Environment) ExportedHash() *Hash {
 	pairs := make(map[HashKey]HashPair)
 
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	for k, v := range e.store {
 		if unicode.IsUpper(rune(k[0])) && !v.SuperGlobal {
 			s := &String{Value: k}
@@ -75,7 +100,9 @@ func (e *Environment) ExportedHash() *Hash {
 
 // Get returns the object bound by name
 func (e *Environment) Get(name string) (Binding, bool) {
+	e.mu.RLock()
 	obj, ok := e.store[name]
+	e.mu.RUnlock()
 
 	if !ok && e.outer != nil {
 		obj, ok = e.outer.Get(name)
@@ -88,7 +115,92 @@ func (e *Environment) Get(name string) (Binding, bool) {
 func (e *Environment) Set(name string, val Object, options BindingOptions) Binding {
 	binding := Binding{Value: val, BindingOptions: options}
 
+	e.mu.Lock()
 	e.store[name] = binding
+	e.mu.Unlock()
 
 	return binding
 }
+
+// Assign rebinds the name in the nearest scope that already binds it. A name
+// that isn't bound anywhere yet is stored in the innermost function or module
+// scope, skipping over any block scopes.
+func (e *Environment) Assign(name string, val Object, options BindingOptions) Binding {
+	binding := Binding{Value: val, BindingOptions: options}
+
+	for env := e; env != nil; env = env.outer {
+		// the binding is checked and replaced at once in each scope
+		env.mu.Lock()
+		_, ok := env.store[name]
+		if ok {
+			env.store[name] = binding
+		}
+		env.mu.Unlock()
+
+		if ok {
+			return binding
+		}
+	}
+
+	env := e
+
+	for env.block {
+		env = env.outer
+	}
+
+	return env.Set(name, val, options)
+}
+
+// Clone returns a copy of the environment and the environments enclosing it
+// holding only the bindings of names, passing their values through copy.
+// clones maps environments to their copies, so that functions closing over the
+// same environment still share it after cloning, each adding the bindings it
+// refers to.
+func (e *Environment) Clone(names map[string]bool, copy func(Object) Object, clones map[*Environment]*Environment) *Environment {
+	clone := e.cloneScopes(clones)
+
+	for name := range names {
+		for scope := e; scope != nil; scope = scope.outer {
+			binding, ok := scope.Lookup(name)
+			if !ok {
+				continue
+			}
+
+			// the clones are not shared yet, so they are filled in without locking
+			if _, copied := clones[scope].store[name]; !copied {
+				binding.Value = copy(binding.Value)
+				clones[scope].store[name] = binding
+			}
+
+			break
+		}
+	}
+
+	return clone
+}
+
+// cloneScopes returns an empty copy of the environment and the environments
+// enclosing it, reusing the copies already in clones
+func (e *Environment) cloneScopes(clones map[*Environment]*Environment) *Environment {
+	if clone, ok := clones[e]; ok {
+		return clone
+	}
+
+	clone := &Environment{store: make(map[string]Binding), block: e.block}
+	clones[e] = clone
+
+	if e.outer != nil {
+		clone.outer = e.outer.cloneScopes(clones)
+	}
+
+	return clone
+}
+
+// Lookup returns the binding of name in this scope only, ignoring enclosing scopes
+func (e *Environment) Lookup(name string) (Binding, bool) {
+	e.mu.RLock()
+	binding, ok := e.store[name]
+	e.mu.RUnlock()
+
+	return binding, ok
+}
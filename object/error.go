@@ -1,7 +1,25 @@
 package object
 
+import (
+	"bytes"
+	"monkey/token"
+)
+
 type Error struct {
-	Message string
+	Message  string
+	Kind     string         // e.g. TypeError, empty for a generic error
+	Value    Object         // the value given to throw, if any
+	File     string         // the file the error was raised in
+	Position token.Position // the position of the node that raised the error
+	Trace    []Frame        // the calls the error unwound through, the most recent call first
+	Cause    error          // the Go error the error was created from, if any
+}
+
+// Frame is a single function call of a traceback
+type Frame struct {
+	Function string
+	File     string
+	Position token.Position // the position of the call
 }
 
 func (e *Error) Type() Type {
@@ -9,5 +27,32 @@ func (e *Error) Type() Type {
 }
 
 func (e *Error) Inspect() string {
-	return "ERROR: " + e.Message
+	if e.Position.Line == 0 {
+		return "ERROR: " + e.Describe()
+	}
+
+	return "ERROR: " + e.File + ":" + e.Position.String() + ": " + e.Describe()
+}
+
+// Describe returns the message prefixed with the kind of the error, if any
+func (e *Error) Describe() string {
+	if e.Kind == "" {
+		return e.Message
+	}
+
+	return e.Kind + ": " + e.Message
+}
+
+// Traceback returns the error followed by the calls it unwound through
+func (e *Error) Traceback() string {
+	var out bytes.Buffer
+
+	out.WriteString(e.Inspect())
+
+	for _, frame := range e.Trace {
+		out.WriteString("\n  in " + frame.Function + "() called from ")
+		out.WriteString(frame.File + ":" + frame.Position.String())
+	}
+
+	return out.String()
 }
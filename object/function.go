@@ -19,9 +19,17 @@ func (rv *ReturnValue) Inspect() string {
 }
 
 type Function struct {
-	Parameters []*ast.Identifier
+	Parameters []ast.Expression
+	Defaults   []ast.Expression
+	Rest       *ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment
+	Generator  bool
+	// UsesArguments exempts the function from the maximum arity check
+	UsesArguments bool
+	// Names are the identifiers the function refers to, the only bindings
+	// copied from its environment when it is spawned
+	Names map[string]bool
 }
 
 func (f *Function) Type() Type {
@@ -32,8 +40,16 @@ func (f *Function) Inspect() string {
 	var out bytes.Buffer
 	var params []string
 
-	for _, p := range f.Parameters {
-		params = append(params, p.String())
+	for i, p := range f.Parameters {
+		if i < len(f.Defaults) && f.Defaults[i] != nil {
+			params = append(params, p.String()+" = "+f.Defaults[i].String())
+		} else {
+			params = append(params, p.String())
+		}
+	}
+
+	if f.Rest != nil {
+		params = append(params, "..."+f.Rest.String())
 	}
 
 	out.WriteString("fn")
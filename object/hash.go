@@ -29,6 +29,12 @@ func (h *Hash) Type() Type {
 }
 
 func (h *Hash) Inspect() string {
+	if InspectHook != nil {
+		if inspected, ok := InspectHook(h); ok {
+			return inspected
+		}
+	}
+
 	var out bytes.Buffer
 	var pairs []string
 
@@ -0,0 +1,20 @@
+package object
+
+// Iterator produces a sequence of values lazily, one at a time. It is
+// returned by calling generator functions, and can only be consumed once.
+type Iterator struct {
+	// Next returns the next value, ok is false once the sequence is
+	// exhausted. An Error value ends the sequence with that error.
+	Next func() (value Object, ok bool)
+	// Close stops an iterator that won't be consumed to the end, releasing
+	// anything it holds. It may be nil.
+	Close func()
+}
+
+func (i *Iterator) Type() Type {
+	return ITERATOR_OBJ
+}
+
+func (i *Iterator) Inspect() string {
+	return "<iterator>"
+}
@@ -0,0 +1,23 @@
+package object
+
+// Break signals that the innermost loop should stop iterating
+type Break struct{}
+
+func (b *Break) Type() Type {
+	return BREAK_OBJ
+}
+
+func (b *Break) Inspect() string {
+	return "break"
+}
+
+// Continue signals that the innermost loop should skip to its next iteration
+type Continue struct{}
+
+func (c *Continue) Type() Type {
+	return CONTINUE_OBJ
+}
+
+func (c *Continue) Inspect() string {
+	return "continue"
+}
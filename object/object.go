@@ -6,10 +6,13 @@ type Type string
 // Object Types
 const (
 	INTEGER_OBJ      Type = "INTEGER"
+	BIGINT_OBJ       Type = "BIGINT"
 	FLOAT_OBJ        Type = "FLOAT"
 	BOOLEAN_OBJ      Type = "BOOLEAN"
 	NULL_OBJ         Type = "NULL"
 	RETURN_VALUE_OBJ Type = "RETURN_VALUE"
+	BREAK_OBJ        Type = "BREAK"
+	CONTINUE_OBJ     Type = "CONTINUE"
 	ERROR_OBJ        Type = "ERROR"
 	FUNCTION_OBJ     Type = "FUNCTION"
 	STRING_OBJ       Type = "STRING"
@@ -17,6 +20,9 @@ const (
 	RESOURCE_OBJ     Type = "RESOURCE"
 	ARRAY_OBJ        Type = "ARRAY"
 	HASH_OBJ         Type = "HASH"
+	ITERATOR_OBJ     Type = "ITERATOR"
+	RANGE_OBJ        Type = "RANGE"
+	CHANNEL_OBJ      Type = "CHANNEL"
 )
 
 // Immutable is the interface for all immutable objects
@@ -29,3 +35,8 @@ type Object interface {
 	Type() Type
 	Inspect() string
 }
+
+// InspectHook returns the string a value converts to when it defines one,
+// e.g. through the __str metamethod of hashes and structs. It is set by the
+// evaluator, which knows how to call functions.
+var InspectHook func(value Object) (string, bool)
@@ -1,6 +1,9 @@
 package object
 
-import "testing"
+import (
+	"math/big"
+	"testing"
+)
 
 func TestStringHashKey(t *testing.T) {
 	hello1 := &String{Value: "Hello World"}
@@ -36,3 +39,17 @@ func testHashKey(t *testing.T, object Hashable) HashKey {
 
 	return key
 }
+
+func TestBigIntHashKey(t *testing.T) {
+	value, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	same := &BigInt{Value: new(big.Int).Set(value)}
+	negated := &BigInt{Value: new(big.Int).Neg(value)}
+
+	if testHashKey(t, &BigInt{Value: value}) != testHashKey(t, same) {
+		t.Errorf("big integers with same value have different hash keys")
+	}
+
+	if testHashKey(t, &BigInt{Value: value}) == testHashKey(t, negated) {
+		t.Errorf("big integers with different signs have same hash keys")
+	}
+}
@@ -0,0 +1,81 @@
+package object
+
+import (
+	"fmt"
+	"math"
+)
+
+// Range is the sequence of integers from Start up to, but not including,
+// End by Step, as returned by range(). Unlike an Iterator it can be iterated
+// any number of times, every iteration starting over from Start. Ranges are
+// made with NewRange, which keeps their length within an int64.
+type Range struct {
+	Start int64
+	End   int64
+	Step  int64
+}
+
+func (r *Range) Type() Type {
+	return RANGE_OBJ
+}
+
+func (r *Range) Inspect() string {
+	if r.Step == 1 {
+		return fmt.Sprintf("range(%d, %d)", r.Start, r.End)
+	}
+
+	return fmt.Sprintf("range(%d, %d, %d)", r.Start, r.End, r.Step)
+}
+
+// NewRange returns the range from start to end by step, which must not be
+// zero. ok is false when the range holds more integers than an int64 counts.
+func NewRange(start, end, step int64) (r *Range, ok bool) {
+	if rangeLength(start, end, step) > math.MaxInt64 {
+		return nil, false
+	}
+
+	return &Range{Start: start, End: end, Step: step}, true
+}
+
+// Len returns the number of integers in the range, counting down for
+// negative steps
+func (r *Range) Len() int64 {
+	return int64(rangeLength(r.Start, r.End, r.Step))
+}
+
+func rangeLength(start, end, step int64) uint64 {
+	var distance, magnitude uint64
+
+	switch {
+	case step > 0 && start < end:
+		distance, magnitude = uint64(end)-uint64(start), uint64(step)
+	case step < 0 && start > end:
+		distance, magnitude = uint64(start)-uint64(end), -uint64(step)
+	default:
+		return 0
+	}
+
+	return (distance-1)/magnitude + 1
+}
+
+// At returns the integer at index i of the range, with 0 <= i < Len()
+func (r *Range) At(i int64) int64 {
+	return r.Start + i*r.Step
+}
+
+// Iterator returns a new iterator over the integers of the range
+func (r *Range) Iterator() *Iterator {
+	i, length := int64(0), r.Len()
+
+	return &Iterator{
+		Next: func() (Object, bool) {
+			if i >= length {
+				return nil, false
+			}
+
+			i++
+
+			return &Integer{Value: r.At(i - 1)}, true
+		},
+	}
+}
@@ -0,0 +1,57 @@
+package object
+
+import (
+	"bytes"
+	"strings"
+)
+
+// StructType is a record type declared with struct, its fields are listed in
+// declaration order
+type StructType struct {
+	Name    string
+	Fields  []string
+	Methods map[string]*Function
+}
+
+// HasField reports whether the type declares a field called name
+func (st *StructType) HasField(name string) bool {
+	for _, field := range st.Fields {
+		if field == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Struct is an instance of a StructType, its type is the name of the struct
+type Struct struct {
+	Definition *StructType
+	Fields     map[string]Object
+}
+
+func (s *Struct) Type() Type {
+	return Type(s.Definition.Name)
+}
+
+func (s *Struct) Inspect() string {
+	if InspectHook != nil {
+		if inspected, ok := InspectHook(s); ok {
+			return inspected
+		}
+	}
+
+	var out bytes.Buffer
+	var fields []string
+
+	for _, name := range s.Definition.Fields {
+		fields = append(fields, name+": "+s.Fields[name].Inspect())
+	}
+
+	out.WriteString(s.Definition.Name)
+	out.WriteString("{")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
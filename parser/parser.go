@@ -1,12 +1,15 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"math/big"
 	"monkey/ast"
 	"monkey/lexer"
 	"monkey/token"
 	"strconv"
+	"strings"
 )
 
 // Precedence represents the binding power of an operator
@@ -17,28 +20,48 @@ const (
 	_           Precedence = iota
 	LOWEST                 // LOWEST
 	ASSIGN                 // =
+	PIPE                   // |>
+	NULLISH                // ??
+	OR                     // ||
+	AND                    // &&
 	EQUALS                 // ==
 	LESSGREATER            // > or <
 	SUM                    // +
 	PRODUCT                // *
 	PREFIX                 // -X or !X
+	EXPONENT               // **
 	CALL                   // myFunction(X)
 	INDEX                  // INDEX
 )
 
 var precedences = map[token.Type]Precedence{
-	token.ASSIGN:   ASSIGN,
-	token.EQ:       EQUALS,
-	token.NOTEQ:    EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.LPAREN:   CALL,
-	token.LBRACKET: INDEX,
-	token.DOT:      INDEX,
+	token.ASSIGN:          ASSIGN,
+	token.PLUS_ASSIGN:     ASSIGN,
+	token.MINUS_ASSIGN:    ASSIGN,
+	token.ASTERISK_ASSIGN: ASSIGN,
+	token.SLASH_ASSIGN:    ASSIGN,
+	token.PERCENT_ASSIGN:  ASSIGN,
+	token.PIPE:            PIPE,
+	token.NULLISH:         NULLISH,
+	token.OR:              OR,
+	token.AND:             AND,
+	token.EQ:              EQUALS,
+	token.NOTEQ:           EQUALS,
+	token.LT:              LESSGREATER,
+	token.GT:              LESSGREATER,
+	token.LTEQ:            LESSGREATER,
+	token.GTEQ:            LESSGREATER,
+	token.PLUS:            SUM,
+	token.MINUS:           SUM,
+	token.SLASH:           PRODUCT,
+	token.ASTERISK:        PRODUCT,
+	token.PERCENT:         PRODUCT,
+	token.DOUBLE_SLASH:    PRODUCT,
+	token.DOUBLE_ASTERISK: EXPONENT,
+	token.LPAREN:          CALL,
+	token.LBRACKET:        INDEX,
+	token.DOT:             INDEX,
+	token.OPTIONAL_CHAIN:  INDEX,
 }
 
 type (
@@ -54,6 +77,8 @@ type Parser struct {
 	l              *lexer.Lexer
 	infixParseFns  map[token.Type]infixParseFn
 	prefixParseFns map[token.Type]prefixParseFn
+	// functions are the function literals being parsed, innermost last
+	functions []*ast.FunctionLiteral
 }
 
 // New creates a new instance of parser
@@ -76,10 +101,16 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.NULL, p.parseNull)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.IF, p.parseIfExpression)
+	p.registerPrefix(token.TRY, p.parseTryExpression)
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
+	p.registerPrefix(token.SELECT, p.parseSelectExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.TEMPLATE, p.parseTemplateLiteral)
+	p.registerPrefix(token.TEMPLATE_PART, p.parseTemplateLiteral)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
+	p.registerPrefix(token.ELLIPSIS, p.parseSpreadExpression)
 
 	// INFIX Operators
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
@@ -90,10 +121,25 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.NOTEQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.LTEQ, p.parseInfixExpression)
+	p.registerInfix(token.GTEQ, p.parseInfixExpression)
+	p.registerInfix(token.PERCENT, p.parseInfixExpression)
+	p.registerInfix(token.DOUBLE_SLASH, p.parseInfixExpression)
+	p.registerInfix(token.DOUBLE_ASTERISK, p.parseInfixExpression)
+	p.registerInfix(token.AND, p.parseInfixExpression)
+	p.registerInfix(token.OR, p.parseInfixExpression)
+	p.registerInfix(token.NULLISH, p.parseInfixExpression)
+	p.registerInfix(token.PIPE, p.parsePipeExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 	p.registerInfix(token.DOT, p.parseSelectorExpression)
+	p.registerInfix(token.OPTIONAL_CHAIN, p.parseOptionalChain)
 	p.registerInfix(token.ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.PLUS_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.MINUS_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.ASTERISK_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.SLASH_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.PERCENT_ASSIGN, p.parseAssignmentExpression)
 
 	// Read two tokens, so curToken and peekToken are both set
 	p.nextToken()
@@ -142,9 +188,12 @@ func (p *Parser) curPrecedence() Precedence {
 }
 
 func (p *Parser) noPrefixParseFnError(t token.Type) {
-	msg := fmt.Sprintf("no prefix parse function for %s found", t)
+	p.addError(p.curToken.Position, "no prefix parse function for %s found", t)
+}
 
-	p.errors = append(p.errors, msg)
+// addError records an error prefixed with the line:column position it occurred at
+func (p *Parser) addError(position token.Position, format string, a ...any) {
+	p.errors = append(p.errors, position.String()+": "+fmt.Sprintf(format, a...))
 }
 
 func (p *Parser) registerPrefix(tokenType token.Type, fn prefixParseFn) {
@@ -159,8 +208,24 @@ func (p *Parser) parseStatement() ast.Statement {
 	switch p.curToken.Type {
 	case token.HASH:
 		return p.parseComment()
+	case token.LET, token.CONST:
+		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.THROW:
+		return p.parseThrowStatement()
+	case token.YIELD:
+		return p.parseYieldStatement()
+	case token.STRUCT:
+		return p.parseStructStatement()
+	case token.WHILE:
+		return p.parseWhileStatement()
+	case token.FOR:
+		return p.parseForStatement()
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -267,14 +332,214 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	return expression
 }
 
+func (p *Parser) parseTryExpression() ast.Expression {
+	expression := &ast.TryExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Block = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.CATCH) {
+		p.nextToken()
+
+		if p.peekTokenIs(token.LPAREN) {
+			p.nextToken()
+
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+
+			expression.Parameter = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+			if !p.expectPeek(token.RPAREN) {
+				return nil
+			}
+		}
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+
+		expression.Catch = p.parseBlockStatement()
+	}
+
+	if p.peekTokenIs(token.FINALLY) {
+		p.nextToken()
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+
+		expression.Finally = p.parseBlockStatement()
+	}
+
+	if expression.Catch == nil && expression.Finally == nil {
+		p.addError(p.peekToken.Position, "expected catch or finally after try block, got %s instead", p.peekToken.Type)
+
+		return nil
+	}
+
+	return expression
+}
+
+func (p *Parser) parseMatchExpression() ast.Expression {
+	expression := &ast.MatchExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+
+	expression.Subject = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		arm := &ast.MatchArm{Pattern: p.parseExpression(LOWEST)}
+
+		if p.peekTokenIs(token.IF) {
+			p.nextToken()
+			p.nextToken()
+
+			arm.Guard = p.parseExpression(LOWEST)
+		}
+
+		if arm.Body = p.parseArmBody(); arm.Body == nil {
+			return nil
+		}
+
+		expression.Arms = append(expression.Arms, arm)
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	return expression
+}
+
+// parseArmBody parses the => and the body of a match arm or select case. The
+// body is either a block, optionally followed by a comma, or a single
+// expression. It returns nil on errors.
+func (p *Parser) parseArmBody() *ast.BlockStatement {
+	if !p.expectPeek(token.ARROW) {
+		return nil
+	}
+
+	p.nextToken()
+
+	var body *ast.BlockStatement
+
+	if p.curTokenIs(token.LBRACE) {
+		body = p.parseBlockStatement()
+	} else {
+		tok := p.curToken
+		stmt := &ast.ExpressionStatement{Token: tok, Expression: p.parseExpression(LOWEST)}
+		body = &ast.BlockStatement{Token: tok, Statements: []ast.Statement{stmt}}
+	}
+
+	if p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+	} else if !p.peekTokenIs(token.RBRACE) && !p.curTokenIs(token.RBRACE) {
+		p.peekError(token.COMMA)
+
+		return nil
+	}
+
+	return body
+}
+
+func (p *Parser) parseSelectExpression() ast.Expression {
+	expression := &ast.SelectExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	hasDefault := false
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		start := p.curToken.Position
+		operation := p.parseExpression(LOWEST)
+		selectCase := &ast.SelectCase{}
+
+		if assignment, ok := operation.(*ast.AssignmentExpression); ok && assignment.Operator == "" {
+			if binding, ok := assignment.Left.(*ast.Identifier); ok {
+				selectCase.Binding = binding
+				operation = assignment.Value
+			}
+		}
+
+		valid := false
+
+		switch operation := operation.(type) {
+		case *ast.Identifier:
+			// the default case
+			if operation.Value == "_" && selectCase.Binding == nil {
+				if hasDefault {
+					p.addError(start, "select has more than one default case")
+
+					return nil
+				}
+
+				hasDefault, valid = true, true
+			}
+		case *ast.CallExpression:
+			function, _ := operation.Function.(*ast.Identifier)
+			selectCase.Operation = operation
+
+			valid = function != nil &&
+				(function.Value == "recv" && len(operation.Arguments) == 1 ||
+					function.Value == "send" && len(operation.Arguments) == 2 && selectCase.Binding == nil)
+		}
+
+		if !valid {
+			p.addError(start, "select case must be recv(channel), send(channel, value) or _")
+
+			return nil
+		}
+
+		if selectCase.Body = p.parseArmBody(); selectCase.Body == nil {
+			return nil
+		}
+
+		expression.Cases = append(expression.Cases, selectCase)
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	return expression
+}
+
 func (p *Parser) parseFunctionLiteral() ast.Expression {
 	lit := &ast.FunctionLiteral{Token: p.curToken}
 
+	// defaults are evaluated in the function too, so they count as its body
+	p.functions = append(p.functions, lit)
+	defer func() { p.functions = p.functions[:len(p.functions)-1] }()
+
 	if !p.expectPeek(token.LPAREN) {
 		return nil
 	}
 
-	lit.Parameters = p.parseFunctionParameters()
+	if !p.parseFunctionParameters(lit) {
+		return nil
+	}
 
 	if !p.expectPeek(token.LBRACE) {
 		return nil
@@ -289,6 +554,31 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+func (p *Parser) parseTemplateLiteral() ast.Expression {
+	template := &ast.TemplateLiteral{Token: p.curToken}
+
+	for {
+		template.Parts = append(template.Parts, &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal})
+
+		if p.curTokenIs(token.TEMPLATE) {
+			return template
+		}
+
+		p.nextToken()
+
+		template.Parts = append(template.Parts, p.parseExpression(LOWEST))
+
+		// the closing brace of the interpolation is lexed with the text following it
+		if !p.peekTokenIs(token.TEMPLATE_PART) && !p.peekTokenIs(token.TEMPLATE) {
+			p.addError(p.peekToken.Position, "expected } after template interpolation, got %s instead", p.peekToken.Type)
+
+			return nil
+		}
+
+		p.nextToken()
+	}
+}
+
 func (p *Parser) parseArrayLiteral() ast.Expression {
 	array := &ast.ArrayLiteral{Token: p.curToken}
 	array.Elements = p.parseExpressionList(token.RBRACKET)
@@ -305,14 +595,26 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 
 		key := p.parseExpression(LOWEST)
 
-		if !p.expectPeek(token.COLON) {
-			return nil
-		}
+		endOfEntry := p.peekTokenIs(token.COMMA) || p.peekTokenIs(token.RBRACE)
+
+		if spread, ok := key.(*ast.SpreadExpression); ok && endOfEntry {
+			hash.Pairs[spread] = spread
+		} else if ident, ok := key.(*ast.Identifier); ok && endOfEntry {
+			// {name} is a shorthand for {"name": name}
+			key = &ast.StringLiteral{Token: ident.Token, Value: ident.Value}
+			hash.Pairs[key] = ident
+		} else {
+			if !p.expectPeek(token.COLON) {
+				return nil
+			}
 
-		p.nextToken()
+			p.nextToken()
 
-		value := p.parseExpression(LOWEST)
-		hash.Pairs[key] = value
+			value := p.parseExpression(LOWEST)
+			hash.Pairs[key] = value
+		}
+
+		hash.Keys = append(hash.Keys, key)
 
 		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
 			return nil
@@ -327,19 +629,87 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 }
 
 func (p *Parser) parseSelectorExpression(exp ast.Expression) ast.Expression {
+	selector := &ast.IndexExpression{Token: p.curToken, Left: exp}
+
 	p.expectPeek(token.IDENT)
 
-	index := &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+	selector.Index = &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+
+	return selector
+}
+
+// parseOptionalChain parses a?.b, a?.[k] and f?.(), which evaluate to null
+// instead of failing when a or f is null
+func (p *Parser) parseOptionalChain(left ast.Expression) ast.Expression {
+	switch {
+	case p.peekTokenIs(token.LBRACKET):
+		p.nextToken()
+
+		switch exp := p.parseIndexExpression(left).(type) {
+		case *ast.IndexExpression:
+			exp.Optional = true
+			return exp
+		case *ast.SliceExpression:
+			exp.Optional = true
+			return exp
+		}
+
+		return nil
+	case p.peekTokenIs(token.LPAREN):
+		p.nextToken()
+
+		exp := p.parseCallExpression(left).(*ast.CallExpression)
+		exp.Optional = true
+
+		return exp
+	default:
+		exp := p.parseSelectorExpression(left).(*ast.IndexExpression)
+		exp.Optional = true
 
-	return &ast.IndexExpression{Left: exp, Index: index}
+		return exp
+	}
 }
 
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
 
+	if !p.peekTokenIs(token.COLON) {
+		p.nextToken()
+
+		exp.Index = p.parseExpression(LOWEST)
+
+		if !p.peekTokenIs(token.COLON) {
+			if !p.expectPeek(token.RBRACKET) {
+				return nil
+			}
+
+			return exp
+		}
+	}
+
+	return p.parseSliceExpression(exp)
+}
+
+// parseSliceExpression parses the rest of xs[start:end:step] from the first
+// colon, index holds the start unless it was omitted
+func (p *Parser) parseSliceExpression(index *ast.IndexExpression) ast.Expression {
+	exp := &ast.SliceExpression{Token: index.Token, Left: index.Left, Start: index.Index}
+
 	p.nextToken()
 
-	exp.Index = p.parseExpression(LOWEST)
+	if !p.peekTokenIs(token.COLON) && !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		exp.End = p.parseExpression(LOWEST)
+	}
+
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+
+		if !p.peekTokenIs(token.RBRACKET) {
+			p.nextToken()
+			exp.Step = p.parseExpression(LOWEST)
+		}
+	}
 
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
@@ -375,16 +745,35 @@ func (p *Parser) parseExpressionList(end token.Type) []ast.Expression {
 
 func (p *Parser) parseAssignmentExpression(exp ast.Expression) ast.Expression {
 	switch node := exp.(type) {
-	case *ast.Identifier, *ast.IndexExpression:
+	case *ast.Identifier:
+	case *ast.IndexExpression:
+		if node.Optional {
+			p.addError(p.curToken.Position, "cannot assign to optional chain %s", node.String())
+
+			return nil
+		}
+	case *ast.ArrayLiteral, *ast.HashLiteral:
+		if !p.curTokenIs(token.ASSIGN) {
+			p.addError(p.curToken.Position, "cannot use %s with a destructuring pattern", p.curToken.Literal)
+
+			return nil
+		}
+
+		if !p.checkPattern(exp, true) {
+			return nil
+		}
 	default:
-		msg := fmt.Sprintf("expected identifier or index expression on left but got %T %#v", node, exp)
-		p.errors = append(p.errors, msg)
+		p.addError(p.curToken.Position, "expected identifier or index expression on left but got %T %#v", node, exp)
 
 		return nil
 	}
 
 	ae := &ast.AssignmentExpression{Token: p.curToken, Left: exp}
 
+	if !p.curTokenIs(token.ASSIGN) {
+		ae.Operator = strings.TrimSuffix(p.curToken.Literal, "=")
+	}
+
 	p.nextToken()
 
 	ae.Value = p.parseExpression(LOWEST)
@@ -392,33 +781,129 @@ func (p *Parser) parseAssignmentExpression(exp ast.Expression) ast.Expression {
 	return ae
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
-	identifiers := []*ast.Identifier{}
+func (p *Parser) parseSpreadExpression() ast.Expression {
+	expression := &ast.SpreadExpression{Token: p.curToken}
 
-	if p.peekTokenIs(token.RPAREN) {
-		p.nextToken()
+	p.nextToken()
+
+	expression.Value = p.parseExpression(PREFIX)
+
+	return expression
+}
+
+// checkPattern reports whether exp is a valid destructuring target, index
+// expressions are only allowed as targets when allowIndex is set
+func (p *Parser) checkPattern(exp ast.Expression, allowIndex bool) bool {
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		return true
+	case *ast.IndexExpression:
+		if allowIndex {
+			return true
+		}
+	case *ast.ArrayLiteral:
+		for i, element := range exp.Elements {
+			if spread, ok := element.(*ast.SpreadExpression); ok {
+				if i != len(exp.Elements)-1 {
+					p.addError(spread.Pos(), "rest element must be last in a destructuring pattern")
+
+					return false
+				}
+
+				element = spread.Value
+			}
+
+			if !p.checkPattern(element, allowIndex) {
+				return false
+			}
+		}
+
+		return true
+	case *ast.HashLiteral:
+		for _, value := range exp.Pairs {
+			if !p.checkPattern(value, allowIndex) {
+				return false
+			}
+		}
 
-		return identifiers
+		return true
+	case nil:
+		return false
 	}
 
-	p.nextToken()
+	p.addError(exp.Pos(), "invalid destructuring target %s", exp.String())
 
-	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	identifiers = append(identifiers, ident)
+	return false
+}
 
-	for p.peekTokenIs(token.COMMA) {
-		p.nextToken()
+// parseFunctionParameters parses the parameters, default values and rest parameter of fn
+func (p *Parser) parseFunctionParameters(fn *ast.FunctionLiteral) bool {
+	fn.Parameters = []ast.Expression{}
+	fn.Defaults = []ast.Expression{}
+
+	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
 
-		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-		identifiers = append(identifiers, ident)
+		return true
 	}
 
-	if !p.expectPeek(token.RPAREN) {
-		return nil
+	for {
+		// the rest parameter must come last
+		if p.peekTokenIs(token.ELLIPSIS) {
+			p.nextToken()
+
+			if !p.expectPeek(token.IDENT) {
+				return false
+			}
+
+			fn.Rest = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+			return p.expectPeek(token.RPAREN)
+		}
+
+		var param ast.Expression
+
+		switch {
+		case p.peekTokenIs(token.LBRACKET):
+			p.nextToken()
+			param = p.parseArrayLiteral()
+		case p.peekTokenIs(token.LBRACE):
+			p.nextToken()
+			param = p.parseHashLiteral()
+		case p.expectPeek(token.IDENT):
+			param = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		default:
+			return false
+		}
+
+		if !p.checkPattern(param, false) {
+			return false
+		}
+
+		var value ast.Expression
+
+		if p.peekTokenIs(token.ASSIGN) {
+			p.nextToken()
+			p.nextToken()
+
+			value = p.parseExpression(LOWEST)
+		} else if len(fn.Defaults) > 0 && fn.Defaults[len(fn.Defaults)-1] != nil {
+			p.addError(param.Pos(), "parameter %s without a default value follows a parameter with one", param.String())
+
+			return false
+		}
+
+		fn.Parameters = append(fn.Parameters, param)
+		fn.Defaults = append(fn.Defaults, value)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+
+		p.nextToken()
 	}
 
-	return identifiers
+	return p.expectPeek(token.RPAREN)
 }
 
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
@@ -440,6 +925,26 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	return block
 }
 
+// parsePipeExpression desugars x |> f(y) into the call f(x, y), and x |> f
+// into f(x)
+func (p *Parser) parsePipeExpression(left ast.Expression) ast.Expression {
+	pipe := p.curToken
+
+	p.nextToken()
+
+	switch right := p.parseExpression(PIPE).(type) {
+	case *ast.CallExpression:
+		call := *right
+		call.Arguments = append([]ast.Expression{left}, right.Arguments...)
+
+		return &call
+	case nil:
+		return nil
+	default:
+		return &ast.CallExpression{Token: pipe, Function: right, Arguments: []ast.Expression{left}}
+	}
+}
+
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{Token: p.curToken, Function: function}
 	exp.Arguments = p.parseExpressionList(token.RPAREN)
@@ -477,6 +982,11 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 
 	precedence := p.curPrecedence()
 
+	// Exponentiation is right-associative: 2 ** 3 ** 2 is 2 ** (3 ** 2)
+	if p.curTokenIs(token.DOUBLE_ASTERISK) {
+		precedence--
+	}
+
 	p.nextToken()
 
 	expression.Right = p.parseExpression(precedence)
@@ -485,6 +995,20 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 }
 
 func (p *Parser) parseIdentifier() ast.Expression {
+	// a function reading its arguments may be called with more than it declares
+	if p.curToken.Literal == "arguments" && len(p.functions) > 0 {
+		p.functions[len(p.functions)-1].UsesArguments = true
+	}
+
+	// the enclosing functions need the binding when the innermost one is created
+	for _, fn := range p.functions {
+		if fn.Names == nil {
+			fn.Names = map[string]bool{}
+		}
+
+		fn.Names[p.curToken.Literal] = true
+	}
+
 	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 }
 
@@ -492,9 +1016,15 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
 
+	if errors.Is(err, strconv.ErrRange) {
+		if bigValue, ok := new(big.Int).SetString(p.curToken.Literal, 0); ok {
+			lit.Big = bigValue
+			return lit
+		}
+	}
+
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.addError(p.curToken.Position, "could not parse %q as integer", p.curToken.Literal)
 
 		return nil
 	}
@@ -508,14 +1038,37 @@ func (p *Parser) parseFloatLiteral() ast.Expression {
 	lit := &ast.FloatLiteral{Token: p.curToken}
 	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
 	if err != nil || math.IsInf(value, 0) {
-		msg := fmt.Sprintf("could not parse %q as float", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.addError(p.curToken.Position, "could not parse %q as float", p.curToken.Literal)
 		return nil
 	}
 	lit.Value = value
 	return lit
 }
 
+func (p *Parser) parseLetStatement() ast.Statement {
+	stmt := &ast.LetStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.ASSIGN) {
+		return nil
+	}
+
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
 
@@ -530,6 +1083,263 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	return stmt
 }
 
+func (p *Parser) parseThrowStatement() ast.Statement {
+	stmt := &ast.ThrowStatement{Token: p.curToken}
+
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseYieldStatement parses a yield, which turns the enclosing function into a generator
+func (p *Parser) parseYieldStatement() ast.Statement {
+	stmt := &ast.YieldStatement{Token: p.curToken}
+
+	if len(p.functions) == 0 {
+		p.addError(p.curToken.Position, "yield outside of a function")
+
+		return nil
+	}
+
+	p.functions[len(p.functions)-1].Generator = true
+
+	if !p.peekTokenIs(token.SEMICOLON) && !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		stmt.Value = p.parseExpression(LOWEST)
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseStructStatement parses a struct declaration, whose members are fields
+// or methods given as name: fn(...) { ... }
+func (p *Parser) parseStructStatement() ast.Statement {
+	stmt := &ast.StructStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	members := map[string]bool{}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		name := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+		if name.Value == "self" {
+			p.addError(name.Token.Position, "self is bound to the instance and cannot be a struct member")
+
+			return nil
+		}
+
+		if members[name.Value] {
+			p.addError(name.Token.Position, "duplicate struct member %s", name.Value)
+
+			return nil
+		}
+
+		members[name.Value] = true
+
+		if p.peekTokenIs(token.COLON) {
+			p.nextToken()
+			p.nextToken()
+
+			start := p.curToken.Position
+
+			function, ok := p.parseExpression(LOWEST).(*ast.FunctionLiteral)
+			if !ok {
+				p.addError(start, "struct method %s must be a function literal", name.Value)
+
+				return nil
+			}
+
+			stmt.Methods = append(stmt.Methods, &ast.StructMethod{Name: name, Function: function})
+		} else {
+			stmt.Fields = append(stmt.Fields, name)
+		}
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseWhileStatement() ast.Statement {
+	stmt := &ast.WhileStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+
+	stmt.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseForStatement() ast.Statement {
+	tok := p.curToken
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+
+	if p.curTokenIs(token.IDENT) && (p.peekTokenIs(token.IN) || p.peekTokenIs(token.COMMA)) {
+		return p.parseForInStatement(tok)
+	}
+
+	stmt := &ast.ForStatement{Token: tok}
+
+	if !p.curTokenIs(token.SEMICOLON) {
+		stmt.Init = p.parseStatement()
+
+		// Expression statements swallow their trailing semicolon, so the
+		// separator is usually the current token at this point.
+		if !p.curTokenIs(token.SEMICOLON) && !p.expectPeek(token.SEMICOLON) {
+			return nil
+		}
+	}
+
+	if !p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+
+		stmt.Condition = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(token.SEMICOLON) {
+		return nil
+	}
+
+	if !p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+
+		stmt.Post = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseForInStatement(tok token.Token) ast.Statement {
+	stmt := &ast.ForInStatement{Token: tok}
+	stmt.Value = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		stmt.Key = stmt.Value
+		stmt.Value = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
+	if !p.expectPeek(token.IN) {
+		return nil
+	}
+
+	p.nextToken()
+
+	stmt.Iterable = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseBreakStatement() ast.Statement {
+	stmt := &ast.BreakStatement{Token: p.curToken}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseContinueStatement() ast.Statement {
+	stmt := &ast.ContinueStatement{Token: p.curToken}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
 func (p *Parser) curTokenIs(t token.Type) bool {
 	return p.curToken.Type == t
 }
@@ -551,9 +1361,8 @@ func (p *Parser) expectPeek(t token.Type) bool {
 }
 
 func (p *Parser) peekError(t token.Type) {
-	msg := fmt.Sprintf("expected next token to be %s, got %s instead",
+	p.addError(p.peekToken.Position, "expected next token to be %s, got %s instead",
 		t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
 }
 
 func (p *Parser) nextToken() {
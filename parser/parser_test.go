@@ -37,6 +37,50 @@ func TestComments(t *testing.T) {
 	}
 }
 
+func TestLetStatements(t *testing.T) {
+	tests := []struct {
+		input              string
+		expectedToken      string
+		expectedIdentifier string
+		expectedValue      any
+	}{
+		{"let x = 5;", "let", "x", 5},
+		{"const y = true;", "const", "y", true},
+		{"let foobar = y", "let", "foobar", "y"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d",
+				len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.LetStatement)
+
+		if !ok {
+			t.Fatalf("stmt not *ast.LetStatement. got=%T", program.Statements[0])
+		}
+
+		if stmt.TokenLiteral() != tt.expectedToken {
+			t.Errorf("stmt.TokenLiteral not %q. got=%q", tt.expectedToken, stmt.TokenLiteral())
+		}
+
+		if !testIdentifier(t, stmt.Name, tt.expectedIdentifier) {
+			return
+		}
+
+		if !testLiteralExpression(t, stmt.Value, tt.expectedValue) {
+			return
+		}
+	}
+}
+
 func TestReturnStatements(t *testing.T) {
 	tests := []struct {
 		input         string
@@ -151,6 +195,57 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestIntegerLiteralBases(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xFF", 255},
+		{"0XfF", 255},
+		{"0o755", 493},
+		{"0b1010", 10},
+		{"1_000_000", 1000000},
+		{"0x_FF", 255},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.IntegerLiteral. got=%T", stmt.Expression)
+		}
+
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %d. got=%d", tt.expected, literal.Value)
+		}
+
+		if literal.TokenLiteral() != tt.input {
+			t.Errorf("literal.TokenLiteral not %s. got=%s", tt.input, literal.TokenLiteral())
+		}
+	}
+
+	big := New(lexer.New("0x1_0000_0000_0000_0000")).ParseProgram()
+	literal := big.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IntegerLiteral)
+	if literal.Big == nil || literal.Big.String() != "18446744073709551616" {
+		t.Errorf("literal.Big not 18446744073709551616. got=%v", literal.Big)
+	}
+
+	for _, input := range []string{"0x", "0b102", "1__000", "1_", "0o8"} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+
+		expected := fmt.Sprintf("1:1: could not parse %q as integer", input)
+		if errors := p.Errors(); len(errors) == 0 || errors[0] != expected {
+			t.Errorf("wrong errors. expected=%q, got=%q", expected, errors)
+		}
+	}
+}
+
 func TestParsingPrefixExpressions(t *testing.T) {
 	prefixTests := []struct {
 		input    string
@@ -227,6 +322,13 @@ func TestParsingInfixExpressions(t *testing.T) {
 		{"true == true", true, "==", true},
 		{"true != false", true, "!=", false},
 		{"false == false", false, "==", false},
+		{"5 <= 5;", 5, "<=", 5},
+		{"5 >= 5;", 5, ">=", 5},
+		{"5 % 5;", 5, "%", 5},
+		{"5 // 5;", 5, "//", 5},
+		{"5 ** 5;", 5, "**", 5},
+		{"true && false", true, "&&", false},
+		{"true || false", true, "||", false},
 	}
 
 	for _, tt := range infixTests {
@@ -360,10 +462,70 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a + b + c * d / f + g)",
 			"add((((a + b) + ((c * d) / f)) + g))",
 		},
+		{
+			"a + b % c // d",
+			"(a + ((b % c) // d))",
+		},
+		{
+			"a <= b == c >= d",
+			"((a <= b) == (c >= d))",
+		},
+		{
+			"a ** b ** c",
+			"(a ** (b ** c))",
+		},
+		{
+			"-a ** b * c",
+			"((-(a ** b)) * c)",
+		},
+		{
+			"a || b && c",
+			"(a || (b && c))",
+		},
+		{
+			"a && b || c && d",
+			"((a && b) || (c && d))",
+		},
+		{
+			"a == b && c < d",
+			"((a == b) && (c < d))",
+		},
 		{
 			"a * [1, 2, 3, 4][b * c] * d",
 			"((a * ([1, 2, 3, 4][(b * c)])) * d)",
 		},
+		{
+			"a[1:b + 1]",
+			"(a[1:(b + 1)])",
+		},
+		{
+			"a[::-1]",
+			"(a[::(-1)])",
+		},
+		{
+			"a[:]",
+			"(a[:])",
+		},
+		{
+			"a ?? b || c",
+			"(a ?? (b || c))",
+		},
+		{
+			"a + b |> f(c) |> g",
+			"g(f((a + b), c))",
+		},
+		{
+			"x = a |> f",
+			"x = f(a);",
+		},
+		{
+			"a?.b?.[c]?.(d) ?? e",
+			"(((a?.[b])?.[c])?.(d) ?? e)",
+		},
+		{
+			"a[i:][0]",
+			"((a[i:])[0])",
+		},
 		{
 			"add(a * b[2], b[1], 2 * [1, 2][1])",
 			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
@@ -385,6 +547,45 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 	}
 }
 
+func TestCompoundAssignmentParsing(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedOperator string
+		expectedString   string
+	}{
+		{"x = 1", "", "x = 1;"},
+		{"x += 1", "+", "x += 1;"},
+		{"x -= 1", "-", "x -= 1;"},
+		{"x *= 1", "*", "x *= 1;"},
+		{"x /= 1", "/", "x /= 1;"},
+		{"x %= 1", "%", "x %= 1;"},
+		{"x[0] += y * 2", "+", "(x[0]) += (y * 2);"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		assignment, ok := stmt.Expression.(*ast.AssignmentExpression)
+
+		if !ok {
+			t.Fatalf("exp not *ast.AssignmentExpression. got=%T", stmt.Expression)
+		}
+
+		if assignment.Operator != tt.expectedOperator {
+			t.Errorf("assignment.Operator not %q. got=%q", tt.expectedOperator, assignment.Operator)
+		}
+
+		if assignment.String() != tt.expectedString {
+			t.Errorf("assignment.String() not %q. got=%q", tt.expectedString, assignment.String())
+		}
+	}
+}
+
 func TestBooleanExpression(t *testing.T) {
 	tests := []struct {
 		input           string
@@ -567,6 +768,373 @@ func TestIfElseExpression(t *testing.T) {
 	}
 }
 
+func TestWhileStatement(t *testing.T) {
+	input := `while (x < y) { x; break; continue; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Body does not contain %d statements. got=%d\n",
+			1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.WhileStatement)
+
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.WhileStatement. got=%T",
+			program.Statements[0])
+	}
+
+	if !testInfixExpression(t, stmt.Condition, "x", "<", "y") {
+		return
+	}
+
+	if len(stmt.Body.Statements) != 3 {
+		t.Fatalf("body is not 3 statements. got=%d\n", len(stmt.Body.Statements))
+	}
+
+	if _, ok := stmt.Body.Statements[1].(*ast.BreakStatement); !ok {
+		t.Errorf("Statements[1] is not ast.BreakStatement. got=%T", stmt.Body.Statements[1])
+	}
+
+	if _, ok := stmt.Body.Statements[2].(*ast.ContinueStatement); !ok {
+		t.Errorf("Statements[2] is not ast.ContinueStatement. got=%T", stmt.Body.Statements[2])
+	}
+}
+
+func TestForStatement(t *testing.T) {
+	tests := []struct {
+		input             string
+		expectedInit      string
+		expectedCondition string
+		expectedPost      string
+	}{
+		{"for (i = 0; i < 10; i = i + 1) { i }", "i = 0;", "(i < 10)", "i = (i + 1);"},
+		{"for (let i = 0; i < 10; i += 1) { i }", "let i = 0;", "(i < 10)", "i += 1;"},
+		{"for (; i < 10;) { i }", "", "(i < 10)", ""},
+		{"for (;;) { i }", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Body does not contain %d statements. got=%d\n",
+				1, len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ForStatement)
+
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ForStatement. got=%T",
+				program.Statements[0])
+		}
+
+		if got := nodeString(stmt.Init); got != tt.expectedInit {
+			t.Errorf("init wrong. expected=%q, got=%q", tt.expectedInit, got)
+		}
+
+		if got := nodeString(stmt.Condition); got != tt.expectedCondition {
+			t.Errorf("condition wrong. expected=%q, got=%q", tt.expectedCondition, got)
+		}
+
+		if got := nodeString(stmt.Post); got != tt.expectedPost {
+			t.Errorf("post wrong. expected=%q, got=%q", tt.expectedPost, got)
+		}
+
+		if !testIdentifier(t, stmt.Body.Statements[0].(*ast.ExpressionStatement).Expression, "i") {
+			return
+		}
+	}
+}
+
+func TestForInStatement(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedKey   string
+		expectedValue string
+	}{
+		{"for (x in xs) { x }", "", "x"},
+		{"for (k, v in xs) { x }", "k", "v"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.ForInStatement)
+
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ForInStatement. got=%T",
+				program.Statements[0])
+		}
+
+		if tt.expectedKey == "" {
+			if stmt.Key != nil {
+				t.Errorf("stmt.Key is not nil. got=%q", stmt.Key)
+			}
+		} else if !testIdentifier(t, stmt.Key, tt.expectedKey) {
+			return
+		}
+
+		if !testIdentifier(t, stmt.Value, tt.expectedValue) {
+			return
+		}
+
+		if !testIdentifier(t, stmt.Iterable, "xs") {
+			return
+		}
+	}
+}
+
+func TestLoopTrailingSemicolon(t *testing.T) {
+	tests := []string{
+		"i = 0; while (i < 10) { i = i + 1 }; i",
+		"for (i = 0; i < 3; i = i + 1) { i }; print(2)",
+		"for (;;) { break }; 1",
+		"for (x in xs) { x }; xs",
+	}
+
+	for _, input := range tests {
+		p := New(lexer.New(input))
+		program := p.ParseProgram()
+
+		checkParserErrors(t, p)
+
+		if len(program.Statements) < 2 {
+			t.Errorf("trailing statement of %q not parsed. got=%d statements", input, len(program.Statements))
+		}
+	}
+}
+
+func TestTryExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"try { x } catch (e) { y }", "try x catch (e) y"},
+		{"try { x } catch { y }", "try x catch y"},
+		{"try { x } finally { z }", "try x finally z"},
+		{"try { x } catch (e) { y } finally { z }", "try x catch (e) y finally z"},
+		{"throw x", "throw x;"},
+		{"throw error(\"boom\")", "throw error(boom);"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestTryExpressionErrors(t *testing.T) {
+	l := lexer.New("try { x }")
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) == 0 {
+		t.Fatalf("expected a parser error for a try without catch or finally")
+	}
+
+	expected := "1:10: expected catch or finally after try block, got EOF instead"
+	if errors[0] != expected {
+		t.Errorf("wrong error. expected=%q, got=%q", expected, errors[0])
+	}
+}
+
+func TestNodePositions(t *testing.T) {
+	input := `x = 1;
+while (x < 10) {
+  x += add(x, 2);
+}`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+
+	loop, ok := program.Statements[1].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("program.Statements[1] is not *ast.WhileStatement. got=%T", program.Statements[1])
+	}
+
+	assignment := loop.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.AssignmentExpression)
+	call := assignment.Value.(*ast.CallExpression)
+
+	tests := []struct {
+		node     ast.Node
+		expected string
+	}{
+		{program, "1:1"},
+		{loop, "2:1"},
+		{loop.Condition, "2:10"},
+		{loop.Body, "2:16"},
+		{assignment, "3:5"},
+		{call.Function, "3:8"},
+		{call.Arguments[1], "3:15"},
+	}
+
+	for i, tt := range tests {
+		if actual := tt.node.Pos().String(); actual != tt.expected {
+			t.Errorf("tests[%d] - position wrong for %q. expected=%s, got=%s", i, tt.node.String(), tt.expected, actual)
+		}
+	}
+}
+
+func TestParserErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x = (1 + 2;", "1:11: expected next token to be ), got ; instead"},
+		{"x = 1;\n  y = }", "2:7: no prefix parse function for } found"},
+		{"if (x) {\n} else 5", "2:8: expected next token to be {, got INT instead"},
+		{"x = 1;\nyield x;", "2:1: yield outside of a function"},
+		{"select { f(a) => 1 }", "1:10: select case must be recv(channel), send(channel, value) or _"},
+		{"select { _ => 1, _ => 2 }", "1:18: select has more than one default case"},
+		{"struct P { x, x }", "1:15: duplicate struct member x"},
+		{"struct P { self }", "1:12: self is bound to the instance and cannot be a struct member"},
+		{"struct P { m: 1 }", "1:15: struct method m must be a function literal"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0])
+		}
+	}
+}
+
+func TestMatchExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		arms     int
+	}{
+		{"match (x) { 1 => a, _ => b }", "match (x) { 1 => a, _ => b }", 2},
+		{"match (x) { n if n > 1 => n, }", "match (x) { n if (n > 1) => n }", 1},
+		{"match (x) { [a, ...rest] => a, {b} => b }", "match (x) { [a, ...rest] => a, {b:b} => b }", 2},
+		{"match (x) { INTEGER(n) => { n } _ => 0 }", "match (x) { INTEGER(n) => n, _ => 0 }", 2},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		match, ok := stmt.Expression.(*ast.MatchExpression)
+		if !ok {
+			t.Fatalf("exp not *ast.MatchExpression. got=%T", stmt.Expression)
+		}
+
+		if len(match.Arms) != tt.arms {
+			t.Errorf("wrong number of arms. expected=%d, got=%d", tt.arms, len(match.Arms))
+		}
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	// arms with an expression body must be separated by commas
+	p := New(lexer.New("match (x) { 1 => a 2 => b }"))
+	p.ParseProgram()
+
+	errors := p.Errors()
+	expected := "1:20: expected next token to be ,, got INT instead"
+	if len(errors) == 0 || errors[0] != expected {
+		t.Errorf("wrong errors. expected=%q, got=%q", expected, errors)
+	}
+}
+
+func TestSelectExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		cases    int
+	}{
+		{"select { v = recv(a) => v, send(b, 1) => 2, _ => 3 }", "select { v = recv(a) => v, send(b, 1) => 2, _ => 3 }", 3},
+		{"select { recv(done) => { x } }", "select { recv(done) => x }", 1},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		sel, ok := stmt.Expression.(*ast.SelectExpression)
+		if !ok {
+			t.Fatalf("exp not *ast.SelectExpression. got=%T", stmt.Expression)
+		}
+
+		if len(sel.Cases) != tt.cases {
+			t.Errorf("wrong number of cases. expected=%d, got=%d", tt.cases, len(sel.Cases))
+		}
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestStructStatementParsing(t *testing.T) {
+	input := "struct Point { x, y, sum: fn() { self.x + self.y }, }"
+
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.StructStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not *ast.StructStatement. got=%T", program.Statements[0])
+	}
+
+	if stmt.Name.Value != "Point" || len(stmt.Fields) != 2 || len(stmt.Methods) != 1 {
+		t.Fatalf("wrong struct. got=%q", stmt.String())
+	}
+
+	expected := "struct Point { x, y, sum: fn() ((self[x]) + (self[y])) }"
+	if actual := program.String(); actual != expected {
+		t.Errorf("expected=%q, got=%q", expected, actual)
+	}
+}
+
 func TestFunctionLiteralParsing(t *testing.T) {
 	input := `fn(x, y) { x + y; }`
 	l := lexer.New(input)
@@ -617,6 +1185,59 @@ func TestFunctionLiteralParsing(t *testing.T) {
 	testInfixExpression(t, bodyStmt.Expression, "x", "+", "y")
 }
 
+func TestArgumentsParsing(t *testing.T) {
+	tests := []struct {
+		input         string
+		usesArguments bool
+	}{
+		{"fn() { arguments }", true},
+		{"fn(a) { if (a) { len(arguments) } }", true},
+		{"fn() { fn() { arguments } }", false},
+		{"fn() { args }", false},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		function := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+
+		if function.UsesArguments != tt.usesArguments {
+			t.Errorf("function.UsesArguments wrong for %q. expected=%t", tt.input, tt.usesArguments)
+		}
+	}
+}
+
+func TestGeneratorParsing(t *testing.T) {
+	tests := []struct {
+		input     string
+		generator bool
+		body      string
+	}{
+		{"fn() { yield 1; yield; }", true, "yield 1;yield;"},
+		{"fn() { if (x) { yield x } }", true, "ifx yield x;"},
+		{"fn() { fn() { yield 1 } }", false, "fn() yield 1;"},
+		{"fn() { x }", false, "x"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		function := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+
+		if function.Generator != tt.generator {
+			t.Errorf("function.Generator wrong for %q. expected=%t", tt.input, tt.generator)
+		}
+
+		if function.Body.String() != tt.body {
+			t.Errorf("function.Body wrong. expected=%q, got=%q", tt.body, function.Body.String())
+		}
+	}
+}
+
 func TestFunctionParameterParsing(t *testing.T) {
 	tests := []struct {
 		input          string
@@ -648,6 +1269,105 @@ func TestFunctionParameterParsing(t *testing.T) {
 	}
 }
 
+func TestDefaultAndRestParameterParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn(a, b = 10) {}", "fn(a, b = 10) "},
+		{"fn(a = 1 + 2, b = a) {}", "fn(a = (1 + 2), b = a) "},
+		{"fn(a, ...rest) {}", "fn(a, ...rest) "},
+		{"fn(...rest) {}", "fn(...rest) "},
+		{"fn(a, b = 1, ...rest) { rest }", "fn(a, b = 1, ...rest) rest"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn(a = 1, b) {}", "1:11: parameter b without a default value follows a parameter with one"},
+		{"fn(...rest, a) {}", "1:11: expected next token to be ), got , instead"},
+		{"fn(a, 1) {}", "1:7: expected next token to be IDENT, got INT instead"},
+	}
+
+	for _, tt := range errorTests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0])
+		}
+	}
+}
+
+func TestDestructuringParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[a, b] = xs", "[a, b] = xs;"},
+		{"[first, ...rest] = xs", "[first, ...rest] = xs;"},
+		{"[a, [b, c]] = xs", "[a, [b, c]] = xs;"},
+		{"[xs[0], b] = ys", "[(xs[0]), b] = ys;"},
+		{"{name} = payload", "{name:name} = payload;"},
+		{"fn([a, b], {c}) { a }", "fn([a, b], {c:c}) a"},
+		{"fn([a, ...rest] = []) { a }", "fn([a, ...rest] = []) a"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"[...rest, a] = xs", "1:2: rest element must be last in a destructuring pattern"},
+		{"[a, 1] = xs", "1:5: invalid destructuring target 1"},
+		{"[a] += xs", "1:5: cannot use += with a destructuring pattern"},
+		{"fn([xs[0]]) {}", "1:7: invalid destructuring target (xs[0])"},
+	}
+
+	for _, tt := range errorTests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0])
+		}
+	}
+}
+
 func TestCallExpressionParsing(t *testing.T) {
 	input := "add(1, 2 * 3, 4 + 5);"
 	l := lexer.New(input)
@@ -708,6 +1428,49 @@ func TestStringLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestTemplateLiteralParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		parts    int
+	}{
+		{"`hello`", "`hello`", 1},
+		{"`hello ${name}!`", "`hello ${name}!`", 3},
+		{"`${a + b}${c}`", "`${(a + b)}${c}`", 5},
+		{"`a ${`b ${c}`} d`", "`a ${`b ${c}`} d`", 3},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		template, ok := stmt.Expression.(*ast.TemplateLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.TemplateLiteral. got=%T", stmt.Expression)
+		}
+
+		if len(template.Parts) != tt.parts {
+			t.Errorf("wrong number of parts. expected=%d, got=%d", tt.parts, len(template.Parts))
+		}
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	p := New(lexer.New("`a ${x y}`"))
+	p.ParseProgram()
+
+	errors := p.Errors()
+	expected := "1:8: expected } after template interpolation, got IDENT instead"
+	if len(errors) == 0 || errors[0] != expected {
+		t.Errorf("wrong errors. expected=%q, got=%q", expected, errors)
+	}
+}
+
 func TestParsingArrayLiterals(t *testing.T) {
 	input := "[1, 2 * 2, 3 + 3]"
 	l := lexer.New(input)
@@ -864,6 +1627,33 @@ func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 	}
 }
 
+func TestParsingHashLiteralsWithSpread(t *testing.T) {
+	input := `{...defaults, "color": "red", ...overrides}`
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+
+	hash, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", program.Statements[0])
+	}
+
+	if len(hash.Keys) != 3 {
+		t.Fatalf("hash.Keys has wrong length. got=%d", len(hash.Keys))
+	}
+
+	for _, i := range []int{0, 2} {
+		if _, ok := hash.Keys[i].(*ast.SpreadExpression); !ok {
+			t.Errorf("hash.Keys[%d] is not ast.SpreadExpression. got=%T", i, hash.Keys[i])
+		}
+	}
+
+	if hash.String() != "{...defaults, color:red, ...overrides}" {
+		t.Errorf("hash.String() wrong. got=%q", hash.String())
+	}
+}
+
 func testComment(t *testing.T, s ast.Statement, expected string) bool {
 	comment, ok := s.(*ast.Comment)
 
@@ -996,6 +1786,14 @@ func testBooleanLiteral(t *testing.T, exp ast.Expression, value bool) bool {
 	return true
 }
 
+func nodeString(node ast.Node) string {
+	if node == nil {
+		return ""
+	}
+
+	return node.String()
+}
+
 func checkParserErrors(t *testing.T, p *Parser) {
 	errors := p.Errors()
 
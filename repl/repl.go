@@ -44,7 +44,9 @@ func Start() {
 		line := scanner.Text()
 		evaluated := evaluator.Run(line, "__REPL__", cwd, true, env)
 
-		if evaluated != nil {
+		if err, ok := evaluated.(*object.Error); ok {
+			fmt.Println(err.Traceback())
+		} else if evaluated != nil {
 			fmt.Println(evaluated.Inspect())
 		}
 	}
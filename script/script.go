@@ -26,8 +26,8 @@ func Start() {
 	env := object.NewEnvironment()
 	evaluated := evaluator.Run(string(data), abs, filepath.Dir(abs), true, env)
 
-	if evaluated != nil && evaluated.Type() == object.ERROR_OBJ {
-		fmt.Println(evaluated.Inspect())
+	if err, ok := evaluated.(*object.Error); ok {
+		fmt.Println(err.Traceback())
 		os.Exit(1)
 	}
 }
@@ -1,5 +1,7 @@
 package token
 
+import "fmt"
+
 // Type represents a type of token
 type Type string
 
@@ -22,6 +24,21 @@ const (
 	// ASSIGN is an assignment token
 	ASSIGN Type = "="
 
+	// PLUS_ASSIGN is an addition assignment token
+	PLUS_ASSIGN Type = "+="
+
+	// MINUS_ASSIGN is a substraction assignment token
+	MINUS_ASSIGN Type = "-="
+
+	// ASTERISK_ASSIGN is a multiplication assignment token
+	ASTERISK_ASSIGN Type = "*="
+
+	// SLASH_ASSIGN is a division assignment token
+	SLASH_ASSIGN Type = "/="
+
+	// PERCENT_ASSIGN is a modulo assignment token
+	PERCENT_ASSIGN Type = "%="
+
 	// PLUS is an addition token
 	PLUS Type = "+"
 
@@ -37,18 +54,45 @@ const (
 	// SLASH is a slash token
 	SLASH Type = "/"
 
+	// PERCENT is a modulo token
+	PERCENT Type = "%"
+
+	// DOUBLE_ASTERISK is an exponentiation token
+	DOUBLE_ASTERISK Type = "**"
+
+	// DOUBLE_SLASH is a floor division token
+	DOUBLE_SLASH Type = "//"
+
 	// LT represents lesser than token
 	LT Type = "<"
 
 	// GT represents greater than token
 	GT Type = ">"
 
+	// LTEQ represents lesser than or equals token
+	LTEQ Type = "<="
+
+	// GTEQ represents greater than or equals token
+	GTEQ Type = ">="
+
 	// EQ represents equals token
 	EQ Type = "=="
 
 	// NOTEQ represents not equals token
 	NOTEQ Type = "!="
 
+	// AND is a logical and token
+	AND Type = "&&"
+
+	// OR is a logical or token
+	OR Type = "||"
+
+	// PIPE is a pipeline token
+	PIPE Type = "|>"
+
+	// NULLISH is a null-coalescing token
+	NULLISH Type = "??"
+
 	// COMMA is a comma token
 	COMMA Type = ","
 
@@ -85,12 +129,68 @@ const (
 	// ELSE is an else statement token
 	ELSE Type = "ELSE"
 
+	// LET is a variable declaration token
+	LET Type = "LET"
+
+	// CONST is a constant declaration token
+	CONST Type = "CONST"
+
 	// RETURN is a return statement token
 	RETURN Type = "RETURN"
 
+	// MATCH is a match expression token
+	MATCH Type = "MATCH"
+
+	// SELECT is a select expression token
+	SELECT Type = "SELECT"
+
+	// STRUCT is a struct declaration token
+	STRUCT Type = "STRUCT"
+
+	// ARROW separates the pattern of a match arm from its body
+	ARROW Type = "=>"
+
+	// TRY is a try expression token
+	TRY Type = "TRY"
+
+	// CATCH is the catch clause token of a try expression
+	CATCH Type = "CATCH"
+
+	// FINALLY is the finally clause token of a try expression
+	FINALLY Type = "FINALLY"
+
+	// THROW is a throw statement token
+	THROW Type = "THROW"
+
+	// YIELD is a yield statement token
+	YIELD Type = "YIELD"
+
+	// WHILE is a while loop token
+	WHILE Type = "WHILE"
+
+	// FOR is a for loop token
+	FOR Type = "FOR"
+
+	// IN is the for-in loop token
+	IN Type = "IN"
+
+	// BREAK is a break statement token
+	BREAK Type = "BREAK"
+
+	// CONTINUE is a continue statement token
+	CONTINUE Type = "CONTINUE"
+
 	// STRING represents a string literal
 	STRING Type = "STRING"
 
+	// TEMPLATE represents a template literal without interpolations or the
+	// text after its last interpolation
+	TEMPLATE Type = "TEMPLATE"
+
+	// TEMPLATE_PART represents the text of a template literal up to an
+	// interpolation, i.e. up to and excluding ${
+	TEMPLATE_PART Type = "TEMPLATE_PART"
+
 	// LBRACKET is the left bracket token
 	LBRACKET Type = "["
 
@@ -105,23 +205,56 @@ const (
 
 	// DOT is a dot token
 	DOT Type = "."
+
+	// OPTIONAL_CHAIN is an optional chaining token
+	OPTIONAL_CHAIN Type = "?."
+
+	// ELLIPSIS is a rest parameter token
+	ELLIPSIS Type = "..."
 )
 
+// Position represents a line and column in the source code, both starting at 1
+type Position struct {
+	Line   int
+	Column int
+}
+
+// String returns the position formatted as line:column
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
 // Token represents a single token
 type Token struct {
 	Type    Type
 	Literal string
+	Position
 }
 
 // keywords map are the supported language keywords
 var keywords = map[string]Type{
-	"fn":     FUNCTION,
-	"true":   TRUE,
-	"false":  FALSE,
-	"null":   NULL,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
+	"fn":       FUNCTION,
+	"true":     TRUE,
+	"false":    FALSE,
+	"null":     NULL,
+	"if":       IF,
+	"else":     ELSE,
+	"let":      LET,
+	"const":    CONST,
+	"return":   RETURN,
+	"match":    MATCH,
+	"select":   SELECT,
+	"struct":   STRUCT,
+	"try":      TRY,
+	"catch":    CATCH,
+	"finally":  FINALLY,
+	"throw":    THROW,
+	"yield":    YIELD,
+	"while":    WHILE,
+	"for":      FOR,
+	"in":       IN,
+	"break":    BREAK,
+	"continue": CONTINUE,
 }
 
 // LookupIdent checks if a string is an identifier
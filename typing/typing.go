@@ -3,8 +3,44 @@ package typing
 import (
 	"fmt"
 	"monkey/object"
+	"strings"
 )
 
+// Kinds of errors reported by the runtime
+const (
+	TypeError     = "TypeError"
+	ArgumentError = "ArgumentError"
+	ValueError    = "ValueError"
+)
+
+type Error struct {
+	Kind    string
+	Message string
+
+	// render formats the message for the function name, leaving out the
+	// first offset arguments from counts and positions
+	render func(name string, offset int) string
+}
+
+func (e *Error) Error() string {
+	return e.Kind + ": " + e.Message
+}
+
+// Method returns the error as reported for a builtin called as the method
+// name, whose receiver is the first argument and isn't counted
+func (e *Error) Method(name string) *Error {
+	if e.render == nil {
+		return e
+	}
+
+	return &Error{Kind: e.Kind, Message: e.render(name, 1), render: e.render}
+}
+
+// newError returns an error with the message render formats for name
+func newError(kind, name string, render func(name string, offset int) string) *Error {
+	return &Error{Kind: kind, Message: render(name, 0), render: render}
+}
+
 type CheckFunc func(name string, args []object.Object) error
 
 func Check(name string, args []object.Object, checks ...CheckFunc) error {
@@ -20,10 +56,12 @@ func Check(name string, args []object.Object, checks ...CheckFunc) error {
 func ExactArgs(n int) CheckFunc {
 	return func(name string, args []object.Object) error {
 		if len(args) != n {
-			return fmt.Errorf(
-				"ArgumentError: %s() takes exactly %d argument (%d given)",
-				name, n, len(args),
-			)
+			return newError(ArgumentError, name, func(name string, offset int) string {
+				return fmt.Sprintf(
+					"%s() takes exactly %d argument (%d given)",
+					name, n-offset, len(args)-offset,
+				)
+			})
 		}
 
 		return nil
@@ -33,10 +71,27 @@ func ExactArgs(n int) CheckFunc {
 func MinimumArgs(n int) CheckFunc {
 	return func(name string, args []object.Object) error {
 		if len(args) < n {
-			return fmt.Errorf(
-				"ArgumentError: %s() takes a minimum %d arguments (%d given)",
-				name, n, len(args),
-			)
+			return newError(ArgumentError, name, func(name string, offset int) string {
+				return fmt.Sprintf(
+					"%s() takes a minimum %d arguments (%d given)",
+					name, n-offset, len(args)-offset,
+				)
+			})
+		}
+
+		return nil
+	}
+}
+
+func MaximumArgs(n int) CheckFunc {
+	return func(name string, args []object.Object) error {
+		if len(args) > n {
+			return newError(ArgumentError, name, func(name string, offset int) string {
+				return fmt.Sprintf(
+					"%s() takes a maximum %d arguments (%d given)",
+					name, n-offset, len(args)-offset,
+				)
+			})
 		}
 
 		return nil
@@ -46,10 +101,12 @@ func MinimumArgs(n int) CheckFunc {
 func RangeOfArgs(n, m int) CheckFunc {
 	return func(name string, args []object.Object) error {
 		if len(args) < n || len(args) > m {
-			return fmt.Errorf(
-				"ArgumentError: %s() takes at least %d arguments and at most %d (%d given)",
-				name, n, m, len(args),
-			)
+			return newError(ArgumentError, name, func(name string, offset int) string {
+				return fmt.Sprintf(
+					"%s() takes at least %d arguments and at most %d (%d given)",
+					name, n-offset, m-offset, len(args)-offset,
+				)
+			})
 		}
 
 		return nil
@@ -60,10 +117,9 @@ func WithTypes(types ...object.Type) CheckFunc {
 	return func(name string, args []object.Object) error {
 		for i, t := range types {
 			if i < len(args) && args[i].Type() != t {
-				return fmt.Errorf(
-					"TypeError: %s() expected argument #%d to be `%s` got `%s`",
-					name, i+1, t, args[i].Type(),
-				)
+				return newError(TypeError, name, func(name string, offset int) string {
+					return mismatch(name, offset, len(args), i+1, "`"+string(t)+"`", args[i].Type())
+				})
 			}
 		}
 
@@ -71,17 +127,58 @@ func WithTypes(types ...object.Type) CheckFunc {
 	}
 }
 
+// ArgumentOfTypes checks that argument #n, when given, is of one of the types
+func ArgumentOfTypes(n int, types ...object.Type) CheckFunc {
+	return func(name string, args []object.Object) error {
+		if n > len(args) {
+			return nil
+		}
+
+		for _, t := range types {
+			if args[n-1].Type() == t {
+				return nil
+			}
+		}
+
+		expected := make([]string, len(types))
+		for i, t := range types {
+			expected[i] = "`" + string(t) + "`"
+		}
+
+		return newError(TypeError, name, func(name string, offset int) string {
+			return mismatch(name, offset, len(args), n, strings.Join(expected, " or "), args[n-1].Type())
+		})
+	}
+}
+
 func AllOfType(t object.Type) CheckFunc {
 	return func(name string, args []object.Object) error {
 		for i, arg := range args {
 			if arg.Type() != t {
-				return fmt.Errorf(
-					"TypeError: %s() expected argument #%d to be `%s` got `%s`",
-					name, i+1, t, arg.Type(),
-				)
+				return newError(TypeError, name, func(name string, offset int) string {
+					return mismatch(name, offset, len(args), i+1, "`"+string(t)+"`", arg.Type())
+				})
 			}
 		}
 
 		return nil
 	}
 }
+
+// mismatch formats the error of argument #n not being of the expected types.
+// When the argument is the receiver of a method, which is left out, the
+// method is reported as missing for the arguments given instead.
+func mismatch(name string, offset, given, n int, expected string, got object.Type) string {
+	if n <= offset {
+		// methods are named after their receiver type, e.g. STRING.split
+		receiver, method, _ := strings.Cut(name, ".")
+
+		if given-offset == 1 {
+			return fmt.Sprintf("%s has no method %s taking 1 argument", receiver, method)
+		}
+
+		return fmt.Sprintf("%s has no method %s taking %d arguments", receiver, method, given-offset)
+	}
+
+	return fmt.Sprintf("%s() expected argument #%d to be %s got `%s`", name, n-offset, expected, got)
+}